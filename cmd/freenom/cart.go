@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tzwsoho/go-freenom/freenom"
+)
+
+// cartItemView cart list 命令输出的购物车项
+type cartItemView struct {
+	Domain       string `json:"domain" yaml:"domain"`
+	Description  string `json:"description" yaml:"description"`
+	Months       int    `json:"months,omitempty" yaml:"months,omitempty"`
+	Price        string `json:"price" yaml:"price"`
+	RegularPrice string `json:"regular_price" yaml:"regular_price"`
+}
+
+// cartView cart list 命令的输出
+type cartView struct {
+	Items    []*cartItemView `json:"items" yaml:"items"`
+	Subtotal string          `json:"subtotal" yaml:"subtotal"`
+	Total    string          `json:"total" yaml:"total"`
+}
+
+// cmdCartList 列出购物车
+func cmdCartList(a *app, args []string) (err error) {
+	if _, err = a.parse(a.flags(), args, 0, 0); nil != err {
+		return
+	}
+
+	var cart *freenom.Cart
+	if cart, err = a.client.CartContext(a.ctx); nil != err {
+		return
+	}
+
+	v := &cartView{
+		Items:    make([]*cartItemView, 0, len(cart.Items)),
+		Subtotal: cart.Subtotal.String(),
+		Total:    cart.Total.String(),
+	}
+
+	t := &table{header: []string{"DOMAIN", "MONTHS", "PRICE", "REGULAR PRICE"}}
+	for _, item := range cart.Items {
+		iv := &cartItemView{
+			Domain:       item.Domain,
+			Description:  item.Description,
+			Months:       item.Months,
+			Price:        item.Price.String(),
+			RegularPrice: item.RegularPrice.String(),
+		}
+
+		name := iv.Domain
+		if "" == name {
+			name = iv.Description
+		}
+
+		v.Items = append(v.Items, iv)
+		t.addRow(name, optionalInt(iv.Months), iv.Price, iv.RegularPrice)
+	}
+	t.addRow("TOTAL", "", v.Total, "")
+
+	return a.out.print(v, t)
+}
+
+// cmdCartAdd 将域名加入购物车
+func cmdCartAdd(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 1, 1); nil != err {
+		return
+	}
+
+	return a.client.AddToCartContext(a.ctx, args[0])
+}
+
+// cmdCartPeriod 修改购物车中域名的注册时长
+func cmdCartPeriod(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 2, 2); nil != err {
+		return
+	}
+
+	months, err := strconv.Atoi(args[1])
+	if nil != err {
+		return fmt.Errorf("invalid months %q", args[1])
+	}
+
+	return a.client.SetCartPeriodContext(a.ctx, args[0], months)
+}
+
+// cmdCartConfigure 设置购物车中域名的域名服务器或 URL 转发
+func cmdCartConfigure(a *app, args []string) (err error) {
+	fs := a.flags()
+	ns := fs.String("ns", "", "comma separated custom nameservers")
+	forward := fs.String("forward", "", "forward the domain to this url")
+	if args, err = a.parse(fs, args, 1, 1); nil != err {
+		return
+	}
+
+	return a.client.ConfigureCartDomainContext(a.ctx, args[0], &freenom.PurchaseOptions{
+		Nameservers: splitList(*ns),
+		ForwardURL:  *forward,
+	})
+}
+
+// cmdCartRemove 从购物车中删除域名
+func cmdCartRemove(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 1, 1); nil != err {
+		return
+	}
+
+	return a.client.RemoveFromCartContext(a.ctx, args[0])
+}
+
+// cmdCartEmpty 清空购物车
+func cmdCartEmpty(a *app, args []string) (err error) {
+	if _, err = a.parse(a.flags(), args, 0, 0); nil != err {
+		return
+	}
+
+	return a.client.EmptyCartContext(a.ctx)
+}
+
+// purchaseView purchase 命令的输出
+type purchaseView struct {
+	Domain      string `json:"domain" yaml:"domain"`
+	OrderNumber string `json:"order_number" yaml:"order_number"`
+}
+
+// cmdPurchase 购买免费域名，reCAPTCHA 令牌由用户在终端中输入
+func cmdPurchase(a *app, args []string) (err error) {
+	fs := a.flags()
+	months := fs.Int("months", 12, "months to register, 1 to 12")
+	ns := fs.String("ns", "", "comma separated custom nameservers")
+	forward := fs.String("forward", "", "forward the domain to this url")
+	if args, err = a.parse(fs, args, 1, 1); nil != err {
+		return
+	}
+
+	a.client.SetCaptchaSolver(freenom.PromptCaptchaSolver(a.stdin, a.stderr))
+
+	var orderNumber string
+	orderNumber, err = a.client.PurchaseFreeDomainContext(a.ctx, args[0], *months, &freenom.PurchaseOptions{
+		Nameservers: splitList(*ns),
+		ForwardURL:  *forward,
+	})
+	if nil != err {
+		return
+	}
+
+	t := &table{header: []string{"DOMAIN", "ORDER"}}
+	t.addRow(args[0], orderNumber)
+	return a.out.print(&purchaseView{Domain: args[0], OrderNumber: orderNumber}, t)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tzwsoho/go-freenom/freenom"
+)
+
+// renewView renew 命令输出的续期结果
+type renewView struct {
+	Domain      string `json:"domain" yaml:"domain"`
+	DomainID    string `json:"domain_id" yaml:"domain_id"`
+	DaysLeft    int    `json:"days_left" yaml:"days_left"`
+	Status      string `json:"status" yaml:"status"`
+	Months      int    `json:"months,omitempty" yaml:"months,omitempty"`
+	OrderNumber string `json:"order_number,omitempty" yaml:"order_number,omitempty"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// cmdRenew 续期即将到期的免费域名，-dry-run 时只列出将会续期及跳过的域名
+// 有域名续期失败或请求出错中止续期时仍输出已有的结果，并以非零退出码退出
+func cmdRenew(a *app, args []string) (err error) {
+	fs := a.flags()
+	months := fs.Int("months", 12, "months to renew, 1 to 12")
+	dryRun := fs.Bool("dry-run", false, "show what would be renewed without renewing")
+	if args, err = a.parse(fs, args, 0, 1); nil != err {
+		return
+	}
+
+	var domain string
+	if 1 == len(args) {
+		domain = args[0]
+	}
+
+	var renewals []*freenom.Renewal
+	if *dryRun {
+		renewals, err = a.client.PlanRenewFreeDomainContext(a.ctx, domain, *months)
+	} else {
+		renewals, err = a.client.RenewFreeDomainContext(a.ctx, domain, *months)
+	}
+	if nil != err && 0 == len(renewals) {
+		return
+	}
+
+	failed := 0
+	views := make([]*renewView, 0, len(renewals))
+	t := &table{header: []string{"DOMAIN", "DAYS LEFT", "STATUS", "MONTHS", "ORDER", "ERROR"}}
+	for _, r := range renewals {
+		v := &renewView{
+			Domain:      r.Domain,
+			DomainID:    r.DomainID,
+			DaysLeft:    r.DaysLeft,
+			Status:      r.Status,
+			Months:      r.Months,
+			OrderNumber: r.OrderNumber,
+		}
+
+		if nil != r.Err {
+			failed++
+			v.Error = r.Err.Error()
+		}
+
+		views = append(views, v)
+		t.addRow(v.Domain, strconv.Itoa(v.DaysLeft), v.Status, optionalInt(v.Months), v.OrderNumber, v.Error)
+	}
+
+	if perr := a.out.print(views, t); nil != perr {
+		return perr
+	}
+
+	// 请求出错中止续期时，已输出的结果中包含出错前提交的续期
+	if nil != err {
+		return
+	}
+
+	if 0 != failed {
+		return fmt.Errorf("%d of %d renewals failed", failed, len(renewals))
+	}
+
+	return
+}
+
+// availabilityView check 及 suggest 命令输出的查询结果
+type availabilityView struct {
+	Domain    string `json:"domain" yaml:"domain"`
+	Status    string `json:"status" yaml:"status"`
+	Type      string `json:"type" yaml:"type"`
+	Price     string `json:"price" yaml:"price"`
+	Currency  string `json:"currency" yaml:"currency"`
+	InCart    bool   `json:"in_cart" yaml:"in_cart"`
+	Available bool   `json:"available" yaml:"available"`
+}
+
+// newAvailabilityView 转换查询结果
+func newAvailabilityView(av *freenom.Availability) *availabilityView {
+	return &availabilityView{
+		Domain:    av.Domain,
+		Status:    av.Status,
+		Type:      av.Type,
+		Price:     fmt.Sprintf("%d.%02d", av.Price.Cents/100, av.Price.Cents%100),
+		Currency:  av.Price.Currency,
+		InCart:    av.InCart,
+		Available: av.Available(),
+	}
+}
+
+// addAvailabilityRow 在表格中添加一行查询结果
+func addAvailabilityRow(t *table, v *availabilityView) {
+	t.addRow(v.Domain, v.Status, v.Type, strings.TrimSpace(v.Price+" "+v.Currency), strconv.FormatBool(v.InCart))
+}
+
+// splitList 拆分以逗号分隔的列表，忽略空项
+func splitList(s string) (list []string) {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); "" != v {
+			list = append(list, v)
+		}
+	}
+
+	return
+}
+
+// cmdCheck 批量查询域名前缀在各后缀下的注册状态及价格
+// 部分前缀查询失败时仍输出其它结果，并以非零退出码退出
+func cmdCheck(a *app, args []string) (err error) {
+	fs := a.flags()
+	tlds := fs.String("tld", "", "comma separated TLDs to show, e.g. .tk,.ml")
+	free := fs.Bool("free", false, "only show domains that can be registered for free")
+	workers := fs.Int("workers", 4, "concurrent checks")
+	rps := fs.Float64("rps", 2, "maximum checks per second, 0 for no limit")
+	if args, err = a.parse(fs, args, 1, -1); nil != err {
+		return
+	}
+
+	results := make(map[string]*freenom.BulkCheckResult)
+	for res := range a.client.CheckAvailabilityBulkContext(a.ctx, args, &freenom.BulkCheckOptions{
+		Workers:           *workers,
+		RequestsPerSecond: *rps,
+		TLDs:              splitList(*tlds),
+	}) {
+		results[res.Prefix] = res
+	}
+
+	prefixes := make([]string, 0, len(results))
+	for p := range results {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+
+	failed := 0
+	views := make([]*availabilityView, 0)
+	t := &table{header: []string{"DOMAIN", "STATUS", "TYPE", "PRICE", "IN CART"}}
+	for _, p := range prefixes {
+		res := results[p]
+		if nil != res.Err {
+			failed++
+			fmt.Fprintf(a.stderr, "freenom: check %s: %v\n", p, res.Err)
+			continue
+		}
+
+		for _, av := range res.Result.Domains {
+			if *free && (!av.Available() || freenom.DomainTypeFree != av.Type) {
+				continue
+			}
+
+			v := newAvailabilityView(av)
+			views = append(views, v)
+			addAvailabilityRow(t, v)
+		}
+	}
+
+	if perr := a.out.print(views, t); nil != perr {
+		return perr
+	}
+
+	if 0 != failed {
+		return fmt.Errorf("%d of %d checks failed", failed, len(prefixes))
+	}
+
+	return
+}
+
+// cmdSuggest 生成并查询可以注册的候选域名
+func cmdSuggest(a *app, args []string) (err error) {
+	fs := a.flags()
+	keywords := fs.String("keywords", "", "comma separated keywords to combine with the prefix")
+	tlds := fs.String("tld", "", "comma separated TLDs in order of preference, e.g. .tk,.ml")
+	paid := fs.Bool("paid", false, "include paid and special domains")
+	limit := fs.Int("limit", 20, "maximum suggestions, 0 for no limit")
+	rps := fs.Float64("rps", 2, "maximum checks per second, 0 for no limit")
+	if args, err = a.parse(fs, args, 1, 1); nil != err {
+		return
+	}
+
+	var suggestions []*freenom.Suggestion
+	suggestions, err = a.client.SuggestDomainsContext(a.ctx, args[0], &freenom.SuggestOptions{
+		Keywords:    splitList(*keywords),
+		TLDs:        splitList(*tlds),
+		IncludePaid: *paid,
+		Limit:       *limit,
+		Bulk:        &freenom.BulkCheckOptions{RequestsPerSecond: *rps},
+	})
+	if nil != err {
+		return
+	}
+
+	views := make([]*availabilityView, 0, len(suggestions))
+	t := &table{header: []string{"DOMAIN", "STATUS", "TYPE", "PRICE", "IN CART"}}
+	for _, s := range suggestions {
+		v := newAvailabilityView(&s.Availability)
+		views = append(views, v)
+		addAvailabilityRow(t, v)
+	}
+
+	return a.out.print(views, t)
+}
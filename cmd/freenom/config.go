@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 环境变量名称
+const (
+	envConfig   string = "FREENOM_CONFIG"
+	envUser     string = "FREENOM_USER"
+	envPassword string = "FREENOM_PASSWORD"
+	envSession  string = "FREENOM_SESSION"
+	envBaseURL  string = "FREENOM_BASE_URL"
+	envOutput   string = "FREENOM_OUTPUT"
+)
+
+// config 命令行工具的设置，优先级为 命令行参数 > 环境变量 > 配置文件
+type config struct {
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Session  string `yaml:"session"`  // 会话文件路径，为空时使用配置目录下的 session.json
+	BaseURL  string `yaml:"base_url"` // Freenom 站点地址，为空时使用默认地址
+	Output   string `yaml:"output"`   // 输出格式：table、json 或 yaml
+}
+
+// configDir 默认的配置目录，即用户配置目录下的 freenom，无法获取用户配置目录时返回空字符串
+func configDir() string {
+	dir, err := os.UserConfigDir()
+	if nil != err {
+		return ""
+	}
+
+	return filepath.Join(dir, "freenom")
+}
+
+// loadConfig 读取 YAML 格式的配置文件
+// 参数 required 为 false 时文件不存在不算错误
+func loadConfig(path string, required bool) (conf *config, err error) {
+	conf = &config{}
+	if "" == path {
+		return
+	}
+
+	var all []byte
+	all, err = ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		return conf, nil
+	} else if nil != err {
+		return nil, fmt.Errorf("read config err: %w", err)
+	}
+
+	if err = yaml.UnmarshalStrict(all, conf); nil != err {
+		return nil, fmt.Errorf("parse config %s err: %w", path, err)
+	}
+
+	return
+}
+
+// override 用非空的值覆盖设置
+func (conf *config) override(other *config) {
+	if "" != other.User {
+		conf.User = other.User
+	}
+
+	if "" != other.Password {
+		conf.Password = other.Password
+	}
+
+	if "" != other.Session {
+		conf.Session = other.Session
+	}
+
+	if "" != other.BaseURL {
+		conf.BaseURL = other.BaseURL
+	}
+
+	if "" != other.Output {
+		conf.Output = other.Output
+	}
+}
+
+// configFromEnv 从环境变量读取设置
+func configFromEnv(getenv func(string) string) *config {
+	return &config{
+		User:     getenv(envUser),
+		Password: getenv(envPassword),
+		Session:  getenv(envSession),
+		BaseURL:  getenv(envBaseURL),
+		Output:   getenv(envOutput),
+	}
+}
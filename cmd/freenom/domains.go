@@ -0,0 +1,407 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom"
+)
+
+const dateLayout string = "2006-01-02"
+
+// domainView domains 命令输出的域名
+type domainView struct {
+	Domain   string `json:"domain" yaml:"domain"`
+	DomainID string `json:"domain_id" yaml:"domain_id"`
+	RegDate  string `json:"reg_date" yaml:"reg_date"`
+	ExpDate  string `json:"exp_date" yaml:"exp_date"`
+	Status   string `json:"status" yaml:"status"`
+	Type     string `json:"type" yaml:"type"`
+}
+
+// recordView records list 命令输出的记录
+type recordView struct {
+	Index    int    `json:"index" yaml:"index"`
+	Type     string `json:"type" yaml:"type"`
+	Name     string `json:"name" yaml:"name"`
+	TTL      int    `json:"ttl" yaml:"ttl"`
+	Value    string `json:"value" yaml:"value"`
+	Priority int    `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Weight   int    `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Port     int    `json:"port,omitempty" yaml:"port,omitempty"`
+}
+
+// formatDate 格式化日期，零值时为空字符串
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(dateLayout)
+}
+
+// optionalInt 表格中的可选数字，0 时为空
+func optionalInt(n int) string {
+	if 0 == n {
+		return ""
+	}
+
+	return strconv.Itoa(n)
+}
+
+// cmdLogin 登录并保存会话
+func cmdLogin(a *app, args []string) (err error) {
+	fs := a.flags()
+	cookies := fs.String("cookies", "", "import the session from a cookies.txt or HAR file exported by a browser")
+	if _, err = a.parse(fs, args, 0, 0); nil != err {
+		return
+	}
+
+	if "" != *cookies {
+		err = a.client.ImportCookiesFileContext(a.ctx, *cookies)
+	} else {
+		err = a.login()
+	}
+
+	if nil == err {
+		a.logged = true
+	}
+
+	return
+}
+
+// cmdDomains 列出域名
+func cmdDomains(a *app, args []string) (err error) {
+	if _, err = a.parse(a.flags(), args, 0, 0); nil != err {
+		return
+	}
+
+	var domains []*freenom.Domain
+	if domains, err = a.client.DomainsContext(a.ctx); nil != err {
+		return
+	}
+
+	views := make([]*domainView, 0, len(domains))
+	t := &table{header: []string{"DOMAIN", "ID", "REGISTERED", "EXPIRES", "STATUS", "TYPE"}}
+	for _, d := range domains {
+		v := &domainView{
+			Domain:   d.Domain,
+			DomainID: d.DomainID,
+			RegDate:  formatDate(d.RegDate),
+			ExpDate:  formatDate(d.ExpDate),
+			Status:   d.Status,
+			Type:     d.Type,
+		}
+
+		views = append(views, v)
+		t.addRow(v.Domain, v.DomainID, v.RegDate, v.ExpDate, v.Status, v.Type)
+	}
+
+	return a.out.print(views, t)
+}
+
+// cmdRecordsList 列出 DNS 记录，序号用于 records modify/delete
+func cmdRecordsList(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 1, 1); nil != err {
+		return
+	}
+
+	var info *freenom.DomainInfo
+	if info, err = a.client.GetDomainInfoContext(a.ctx, args[0]); nil != err {
+		return
+	}
+
+	views := make([]*recordView, 0, len(info.Records))
+	t := &table{header: []string{"#", "TYPE", "NAME", "TTL", "VALUE", "PRIORITY", "WEIGHT", "PORT"}}
+	for i, r := range info.Records {
+		v := &recordView{
+			Index:    i,
+			Type:     r.Type,
+			Name:     r.Name,
+			TTL:      r.TTL,
+			Value:    r.Value,
+			Priority: r.Priority,
+			Weight:   r.Weight,
+			Port:     r.Port,
+		}
+
+		views = append(views, v)
+		t.addRow(strconv.Itoa(i), v.Type, v.Name, strconv.Itoa(v.TTL), v.Value,
+			optionalInt(v.Priority), optionalInt(v.Weight), optionalInt(v.Port))
+	}
+
+	return a.out.print(views, t)
+}
+
+// recordFlags 在 fs 中添加记录各字段的选项，默认值取自 r，解析后直接写入 r
+func recordFlags(fs *flag.FlagSet, r *freenom.DomainRecord) {
+	fs.StringVar(&r.Type, "type", r.Type, "record type: A, AAAA, CNAME, MX, TXT, SRV ...")
+	fs.StringVar(&r.Name, "name", r.Name, "record name, empty for the domain itself")
+	fs.StringVar(&r.Value, "value", r.Value, "record value")
+	fs.IntVar(&r.TTL, "ttl", r.TTL, "TTL in seconds")
+	fs.IntVar(&r.Priority, "priority", r.Priority, "priority of MX and SRV records")
+	fs.IntVar(&r.Weight, "weight", r.Weight, "weight of SRV records")
+	fs.IntVar(&r.Port, "port", r.Port, "port of SRV records")
+}
+
+// cmdRecordsAdd 添加一条 DNS 记录
+func cmdRecordsAdd(a *app, args []string) (err error) {
+	record := freenom.DomainRecord{
+		Type: freenom.RecordTypeA,
+		TTL:  3600,
+	}
+
+	fs := a.flags()
+	recordFlags(fs, &record)
+	if args, err = a.parse(fs, args, 1, 1); nil != err {
+		return
+	}
+
+	if "" == record.Value {
+		fs.Usage()
+		return errUsage
+	}
+
+	return a.client.AddRecordContext(a.ctx, args[0], []freenom.DomainRecord{record})
+}
+
+// recordAt 获取域名的第 index 条记录，index 为 records list 中的序号
+func recordAt(a *app, domain, index string) (record *freenom.DomainRecord, err error) {
+	i, err := strconv.Atoi(index)
+	if nil != err {
+		return nil, fmt.Errorf("invalid record index %q", index)
+	}
+
+	var info *freenom.DomainInfo
+	if info, err = a.client.GetDomainInfoContext(a.ctx, domain); nil != err {
+		return
+	}
+
+	if i < 0 || i >= len(info.Records) {
+		return nil, fmt.Errorf("record %d of %s: %w", i, domain, freenom.ErrRecordNotFound)
+	}
+
+	return info.Records[i], nil
+}
+
+// cmdRecordsModify 修改一条 DNS 记录，没有指定的字段保持不变
+func cmdRecordsModify(a *app, args []string) (err error) {
+	var newRecord freenom.DomainRecord
+
+	fs := a.flags()
+	recordFlags(fs, &newRecord)
+	if args, err = a.parse(fs, args, 2, 2); nil != err {
+		return
+	}
+
+	var oldRecord *freenom.DomainRecord
+	if oldRecord, err = recordAt(a, args[0], args[1]); nil != err {
+		return
+	}
+
+	// 以原记录为基础，只替换命令行中指定的字段
+	set := newRecord
+	newRecord = *oldRecord
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "type":
+			newRecord.Type = set.Type
+		case "name":
+			newRecord.Name = set.Name
+		case "value":
+			newRecord.Value = set.Value
+		case "ttl":
+			newRecord.TTL = set.TTL
+		case "priority":
+			newRecord.Priority = set.Priority
+		case "weight":
+			newRecord.Weight = set.Weight
+		case "port":
+			newRecord.Port = set.Port
+		}
+	})
+
+	return a.client.ModifyRecordContext(a.ctx, args[0], oldRecord, &newRecord)
+}
+
+// cmdRecordsDelete 删除一条 DNS 记录
+func cmdRecordsDelete(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 2, 2); nil != err {
+		return
+	}
+
+	var record *freenom.DomainRecord
+	if record, err = recordAt(a, args[0], args[1]); nil != err {
+		return
+	}
+
+	return a.client.DeleteRecordContext(a.ctx, args[0], record)
+}
+
+// nameserversView nameservers get 命令的输出
+type nameserversView struct {
+	Custom bool     `json:"custom" yaml:"custom"`
+	Hosts  []string `json:"hosts" yaml:"hosts"`
+}
+
+// cmdNameserversGet 显示域名服务器
+func cmdNameserversGet(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 1, 1); nil != err {
+		return
+	}
+
+	var ns *freenom.Nameservers
+	if ns, err = a.client.GetNameserversContext(a.ctx, args[0]); nil != err {
+		return
+	}
+
+	t := &table{header: []string{"NAMESERVER", "CUSTOM"}}
+	for _, h := range ns.Hosts {
+		t.addRow(h, strconv.FormatBool(ns.Custom))
+	}
+
+	return a.out.print(&nameserversView{Custom: ns.Custom, Hosts: ns.Hosts}, t)
+}
+
+// cmdNameserversSet 使用自定义域名服务器
+func cmdNameserversSet(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 2, -1); nil != err {
+		return
+	}
+
+	return a.client.SetNameserversContext(a.ctx, args[0], args[1:])
+}
+
+// cmdNameserversDefault 使用 Freenom 默认域名服务器
+func cmdNameserversDefault(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 1, 1); nil != err {
+		return
+	}
+
+	return a.client.UseDefaultNameserversContext(a.ctx, args[0])
+}
+
+// forwardView forward get 命令的输出
+type forwardView struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+	Type    string `json:"type,omitempty" yaml:"type,omitempty"` // redirect 或 frame
+	Title   string `json:"title,omitempty" yaml:"title,omitempty"`
+}
+
+// cmdForwardGet 显示 URL 转发设置
+func cmdForwardGet(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 1, 1); nil != err {
+		return
+	}
+
+	var fwd *freenom.URLForwarding
+	if fwd, err = a.client.GetURLForwardingContext(a.ctx, args[0]); nil != err {
+		return
+	}
+
+	v := &forwardView{
+		Enabled: fwd.Enabled,
+		URL:     fwd.URL,
+		Title:   fwd.Title,
+	}
+
+	if fwd.Enabled {
+		v.Type = "redirect"
+		if freenom.ForwardTypeFrame == fwd.Type {
+			v.Type = "frame"
+		}
+	}
+
+	t := &table{header: []string{"ENABLED", "URL", "TYPE", "TITLE"}}
+	t.addRow(strconv.FormatBool(v.Enabled), v.URL, v.Type, v.Title)
+	return a.out.print(v, t)
+}
+
+// cmdForwardSet 设置 URL 转发
+func cmdForwardSet(a *app, args []string) (err error) {
+	fs := a.flags()
+	frame := fs.Bool("frame", false, "show the target in a frame instead of a 301 redirect")
+	title := fs.String("title", "", "page title of the frame")
+	if args, err = a.parse(fs, args, 2, 2); nil != err {
+		return
+	}
+
+	fwd := &freenom.URLForwarding{
+		Enabled: true,
+		URL:     args[1],
+		Type:    freenom.ForwardTypeRedirect,
+	}
+
+	if *frame {
+		fwd.Type = freenom.ForwardTypeFrame
+		fwd.Title = *title
+	}
+
+	return a.client.SetURLForwardingContext(a.ctx, args[0], fwd)
+}
+
+// cmdForwardDisable 关闭 URL 转发，切换回 Freenom DNS
+func cmdForwardDisable(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 1, 1); nil != err {
+		return
+	}
+
+	return a.client.SetURLForwardingContext(a.ctx, args[0], &freenom.URLForwarding{})
+}
+
+// glueView glue list 命令输出的子域名服务器
+type glueView struct {
+	Host string `json:"host" yaml:"host"`
+	IP   string `json:"ip" yaml:"ip"`
+}
+
+// cmdGlueList 列出子域名服务器
+func cmdGlueList(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 1, 1); nil != err {
+		return
+	}
+
+	var records []*freenom.GlueRecord
+	if records, err = a.client.ListGlueRecordsContext(a.ctx, args[0]); nil != err {
+		return
+	}
+
+	views := make([]*glueView, 0, len(records))
+	t := &table{header: []string{"HOST", "IP"}}
+	for _, r := range records {
+		views = append(views, &glueView{Host: r.Host, IP: r.IP})
+		t.addRow(r.Host, r.IP)
+	}
+
+	return a.out.print(views, t)
+}
+
+// cmdGlueCreate 注册子域名服务器
+func cmdGlueCreate(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 3, 3); nil != err {
+		return
+	}
+
+	return a.client.CreateGlueRecordContext(a.ctx, args[0], args[1], args[2])
+}
+
+// cmdGlueUpdate 修改子域名服务器的 IP
+func cmdGlueUpdate(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 4, 4); nil != err {
+		return
+	}
+
+	return a.client.UpdateGlueRecordContext(a.ctx, args[0], args[1], args[2], args[3])
+}
+
+// cmdGlueDelete 删除子域名服务器
+func cmdGlueDelete(a *app, args []string) (err error) {
+	if args, err = a.parse(a.flags(), args, 2, 2); nil != err {
+		return
+	}
+
+	return a.client.DeleteGlueRecordContext(a.ctx, args[0], args[1])
+}
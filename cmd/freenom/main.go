@@ -0,0 +1,377 @@
+/*
+freenom 是管理 Freenom 域名的命令行工具
+
+用法：
+
+	freenom [全局参数] <命令> [参数]
+
+账号、密码等设置依次从命令行参数、环境变量（FREENOM_USER、FREENOM_PASSWORD 等）及
+配置文件（默认为用户配置目录下的 freenom/config.yaml）中读取。
+登录后的会话保存在会话文件中，之后的命令会复用该会话，会话过期时自动重新登录。
+
+使用 freenom help 查看所有命令。
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tzwsoho/go-freenom/freenom"
+)
+
+// 退出码
+const (
+	exitOK    int = 0
+	exitError int = 1
+	exitUsage int = 2
+)
+
+// errUsage 命令行参数不正确，已输出用法
+var errUsage = errors.New("usage")
+
+// command 子命令
+type command struct {
+	name  string // 命令名称，可以包含空格，例如 records list
+	args  string // 参数说明
+	help  string // 命令说明
+	login bool   // 是否需要登录
+	alias bool   // 其他命令的别名，不在帮助中列出
+	run   func(a *app, args []string) error
+}
+
+// app 一次命令行调用的上下文
+type app struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	conf   *config
+	out    *printer
+	client *freenom.Client
+	cmd    *command
+	logged bool // 是否已恢复会话或登录
+}
+
+func main() {
+	ctx, stop := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		stop()
+	}()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run 解析全局参数并执行子命令
+// 返回 退出码
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	a := &app{
+		ctx:    ctx,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	fs := flag.NewFlagSet("freenom", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { a.usage() }
+
+	flags := &config{}
+	configPath := fs.String("config", "", "config file (env "+envConfig+")")
+	fs.StringVar(&flags.User, "user", "", "account email (env "+envUser+")")
+	fs.StringVar(&flags.Password, "password", "", "account password (env "+envPassword+")")
+	fs.StringVar(&flags.Session, "session", "", "session file (env "+envSession+")")
+	fs.StringVar(&flags.BaseURL, "base-url", "", "Freenom site url (env "+envBaseURL+")")
+	fs.StringVar(&flags.Output, "o", "", "output format: table, json or yaml (env "+envOutput+")")
+
+	if err := fs.Parse(args); nil != err {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+
+		return exitUsage
+	}
+
+	if 0 == fs.NArg() {
+		a.usage()
+		return exitUsage
+	}
+
+	// 配置文件
+	path, required := *configPath, true
+	if "" == path {
+		path = getenv(envConfig)
+	}
+
+	if "" == path {
+		if dir := configDir(); "" != dir {
+			path, required = filepath.Join(dir, "config.yaml"), false
+		}
+	}
+
+	var err error
+	a.conf, err = loadConfig(path, required)
+	if nil != err {
+		fmt.Fprintln(stderr, "freenom:", err)
+		return exitError
+	}
+
+	a.conf.override(configFromEnv(getenv))
+	a.conf.override(flags)
+
+	if "" == a.conf.Session {
+		if dir := configDir(); "" != dir {
+			a.conf.Session = filepath.Join(dir, "session.json")
+		}
+	}
+
+	if a.out, err = newPrinter(a.conf.Output, stdout); nil != err {
+		fmt.Fprintln(stderr, "freenom:", err)
+		return exitUsage
+	}
+
+	var rest []string
+	a.cmd, rest = findCommand(fs.Args())
+	if nil == a.cmd {
+		fmt.Fprintf(stderr, "freenom: unknown command %q\n", strings.Join(fs.Args(), " "))
+		a.usage()
+		return exitUsage
+	}
+
+	if err = a.exec(rest); nil != err {
+		if errors.Is(err, errUsage) {
+			return exitUsage
+		}
+
+		fmt.Fprintln(stderr, "freenom:", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// exec 创建客户端并执行子命令，登录过时保存会话
+func (a *app) exec(args []string) (err error) {
+	opts := []freenom.Option{
+		freenom.WithCredentials(a.conf.User, a.conf.Password),
+	}
+
+	if "" != a.conf.BaseURL {
+		opts = append(opts, freenom.WithBaseURL(a.conf.BaseURL))
+	}
+
+	a.client, err = freenom.NewClient(opts...)
+	if nil != err {
+		return
+	}
+
+	err = a.cmd.run(a, args)
+
+	if a.logged {
+		if e := a.saveSession(); nil != e && nil == err {
+			err = e
+		}
+	}
+
+	return
+}
+
+// restoreSession 从会话文件恢复会话，会话无效时使用账号密码登录
+func (a *app) restoreSession() (err error) {
+	if "" != a.conf.Session {
+		if _, err = os.Stat(a.conf.Session); nil == err {
+			return a.client.RestoreSessionContext(a.ctx, a.conf.Session)
+		}
+	}
+
+	return a.login()
+}
+
+// login 使用账号密码登录
+func (a *app) login() (err error) {
+	if "" == a.conf.User || "" == a.conf.Password {
+		return fmt.Errorf("account not set: use -user/-password, %s/%s or the config file", envUser, envPassword)
+	}
+
+	return a.client.LoginContext(a.ctx)
+}
+
+// saveSession 将会话保存到会话文件
+func (a *app) saveSession() (err error) {
+	if "" == a.conf.Session {
+		return
+	}
+
+	if _, err = a.client.Session(); nil != err { // 没有登录成功时不保存
+		return nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(a.conf.Session), 0700); nil != err {
+		return
+	}
+
+	return a.client.SaveSessionFile(a.conf.Session)
+}
+
+// flags 为子命令创建参数解析器
+func (a *app) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet(a.cmd.name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "usage: freenom %s %s\n\n%s\n", a.cmd.name, a.cmd.args, a.cmd.help)
+
+		n := 0
+		fs.VisitAll(func(*flag.Flag) { n++ })
+		if 0 != n {
+			fmt.Fprintln(a.stderr)
+			fs.PrintDefaults()
+		}
+	}
+
+	return fs
+}
+
+// parse 解析子命令的参数，选项可以放在参数前后，参数个数不在 [min, max] 之间时输出用法，max 小于 0 时不限制
+// 参数正确且子命令需要登录时恢复会话或登录
+// 返回 去掉选项后的参数
+func (a *app) parse(fs *flag.FlagSet, args []string, min, max int) (rest []string, err error) {
+	for {
+		if err = fs.Parse(args); nil != err {
+			return nil, errUsage
+		}
+
+		if 0 == fs.NArg() {
+			break
+		}
+
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(rest) < min || (max >= 0 && len(rest) > max) {
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	if a.cmd.login && !a.logged {
+		a.logged = true
+		err = a.restoreSession()
+	}
+
+	return
+}
+
+// usage 输出所有命令
+func (a *app) usage() {
+	fmt.Fprint(a.stderr, `usage: freenom [global flags] <command> [args]
+
+global flags:
+  -config file     config file (env FREENOM_CONFIG, default <user config dir>/freenom/config.yaml)
+  -user email      account email (env FREENOM_USER)
+  -password pwd    account password (env FREENOM_PASSWORD)
+  -session file    session file (env FREENOM_SESSION, default <user config dir>/freenom/session.json)
+  -base-url url    Freenom site url (env FREENOM_BASE_URL)
+  -o format        output format: table, json or yaml (env FREENOM_OUTPUT, default table)
+
+commands:
+`)
+
+	tw := newHelpWriter(a.stderr)
+	for _, cmd := range commands {
+		if cmd.alias {
+			continue
+		}
+
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.help)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(a.stderr, "\nuse \"freenom <command> -h\" for the flags of a command")
+}
+
+// findCommand 按最长匹配查找子命令
+// 返回 子命令及其余参数，找不到时返回 nil
+func findCommand(args []string) (cmd *command, rest []string) {
+	best := 0
+	for _, c := range commands {
+		words := strings.Fields(c.name)
+		if len(words) <= best || len(words) > len(args) {
+			continue
+		}
+
+		match := true
+		for i, w := range words {
+			if w != args[i] {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			cmd, best = c, len(words)
+		}
+	}
+
+	if nil == cmd {
+		return
+	}
+
+	return cmd, args[best:]
+}
+
+// commands 所有子命令，按名称排序后用于输出帮助
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "help", help: "show this help", run: cmdHelp},
+		{name: "login", args: "[-cookies file]", help: "log in and save the session", run: cmdLogin},
+		{name: "domains list", help: "list domains", login: true, run: cmdDomains},
+		{name: "domains", help: "list domains", login: true, alias: true, run: cmdDomains},
+		{name: "records list", args: "<domain>", help: "list DNS records", login: true, run: cmdRecordsList},
+		{name: "records add", args: "<domain> -type A -name www -value 1.2.3.4", help: "add a DNS record", login: true, run: cmdRecordsAdd},
+		{name: "records modify", args: "<domain> <index> [-value v] ...", help: "modify the DNS record at index", login: true, run: cmdRecordsModify},
+		{name: "records delete", args: "<domain> <index>", help: "delete the DNS record at index", login: true, run: cmdRecordsDelete},
+		{name: "renew", args: "[-months 12] [-dry-run] [domain]", help: "renew free domains expiring within 14 days", login: true, run: cmdRenew},
+		{name: "check", args: "[-tld .tk,.ml] <prefix>...", help: "check availability and prices", run: cmdCheck},
+		{name: "suggest", args: "[-keywords a,b] <prefix>", help: "suggest available alternatives", run: cmdSuggest},
+		{name: "nameservers get", args: "<domain>", help: "show nameservers", login: true, run: cmdNameserversGet},
+		{name: "nameservers set", args: "<domain> <host>...", help: "use custom nameservers", login: true, run: cmdNameserversSet},
+		{name: "nameservers default", args: "<domain>", help: "use Freenom default nameservers", login: true, run: cmdNameserversDefault},
+		{name: "forward get", args: "<domain>", help: "show URL forwarding", login: true, run: cmdForwardGet},
+		{name: "forward set", args: "<domain> <url> [-frame]", help: "forward the domain to url", login: true, run: cmdForwardSet},
+		{name: "forward disable", args: "<domain>", help: "switch back to Freenom DNS", login: true, run: cmdForwardDisable},
+		{name: "glue list", args: "<domain>", help: "list glue records", login: true, run: cmdGlueList},
+		{name: "glue create", args: "<domain> <host> <ip>", help: "register a child nameserver", login: true, run: cmdGlueCreate},
+		{name: "glue update", args: "<domain> <host> <old ip> <new ip>", help: "change the IP of a child nameserver", login: true, run: cmdGlueUpdate},
+		{name: "glue delete", args: "<domain> <host>", help: "delete a child nameserver", login: true, run: cmdGlueDelete},
+		{name: "cart list", help: "list the shopping cart", login: true, run: cmdCartList},
+		{name: "cart add", args: "<domain>", help: "add a domain to the cart", login: true, run: cmdCartAdd},
+		{name: "cart period", args: "<domain> <months>", help: "set the registration period", login: true, run: cmdCartPeriod},
+		{name: "cart configure", args: "<domain> [-ns a,b] [-forward url]", help: "set nameservers or URL forwarding", login: true, run: cmdCartConfigure},
+		{name: "cart remove", args: "<domain>", help: "remove a domain from the cart", login: true, run: cmdCartRemove},
+		{name: "cart empty", help: "empty the cart", login: true, run: cmdCartEmpty},
+		{name: "purchase", args: "[-months 12] [-ns a,b] [-forward url] <domain>", help: "register a free domain, asks for reCAPTCHA tokens", login: true, run: cmdPurchase},
+	}
+
+	sort.SliceStable(commands, func(i, j int) bool {
+		return strings.Fields(commands[i].name)[0] < strings.Fields(commands[j].name)[0]
+	})
+}
+
+// cmdHelp 输出所有命令
+func cmdHelp(a *app, args []string) error {
+	a.usage()
+	return nil
+}
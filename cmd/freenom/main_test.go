@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom"
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
+)
+
+const (
+	testUser   string = "freenomapi@gmail.com"
+	testPwd    string = "AaBbCc!1@2#3"
+	testDomain string = "freenom-api.tk"
+)
+
+// testEnv 测试使用的模拟服务器、临时目录及环境变量
+type testEnv struct {
+	t   *testing.T
+	srv *freenomtest.Server
+	dir string
+	env map[string]string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	srv := freenomtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddAccount(testUser, testPwd)
+	srv.AddDomain(testUser, freenomtest.Domain{
+		Name:    testDomain,
+		RegDate: time.Now().AddDate(-1, 0, 10),
+		ExpDate: time.Now().AddDate(0, 0, 10),
+		Records: []freenomtest.Record{
+			{Type: "A", Name: "", TTL: 3600, Value: "10.0.0.1"},
+		},
+	})
+
+	dir, err := ioutil.TempDir("", "freenom-cli")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	// 通过 FREENOM_CONFIG 指定的配置文件必须存在
+	if err = ioutil.WriteFile(filepath.Join(dir, "config.yaml"), nil, 0600); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	return &testEnv{
+		t:   t,
+		srv: srv,
+		dir: dir,
+		env: map[string]string{
+			envConfig:  filepath.Join(dir, "config.yaml"),
+			envSession: filepath.Join(dir, "session.json"),
+			envBaseURL: srv.URL,
+		},
+	}
+}
+
+// run 执行命令，返回退出码、标准输出及标准错误
+func (e *testEnv) run(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, strings.NewReader(""), &out, &errOut, func(k string) string {
+		return e.env[k]
+	})
+
+	return code, out.String(), errOut.String()
+}
+
+// mustRun 执行命令，失败时终止测试
+func (e *testEnv) mustRun(args ...string) string {
+	code, stdout, stderr := e.run(args...)
+	if exitOK != code {
+		e.t.Fatalf("%v: exit %d: %s", args, code, stderr)
+	}
+
+	return stdout
+}
+
+func TestCredentialSources(t *testing.T) {
+	e := newTestEnv(t)
+
+	if code, _, stderr := e.run("domains"); exitError != code || !strings.Contains(stderr, "account not set") {
+		t.Errorf("expect account error, got %d %s", code, stderr)
+	}
+
+	// 配置文件
+	conf := "user: " + testUser + "\npassword: wrong\n"
+	if err := ioutil.WriteFile(e.env[envConfig], []byte(conf), 0600); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if code, _, _ := e.run("login"); exitError != code {
+		t.Errorf("expect login with wrong password to fail, got %d", code)
+	}
+
+	// 环境变量覆盖配置文件
+	e.env[envPassword] = "wrong too"
+	if code, _, _ := e.run("login"); exitError != code {
+		t.Errorf("expect login with wrong password to fail, got %d", code)
+	}
+
+	// 命令行参数覆盖环境变量
+	e.mustRun("-password", testPwd, "login")
+
+	// 之后的命令复用会话文件，不再登录
+	logins := e.srv.Logins()
+	if out := e.mustRun("domains", "list"); !strings.Contains(out, testDomain) {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	// domains 是 domains list 的别名
+	if out := e.mustRun("domains"); !strings.Contains(out, testDomain) {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	if logins != e.srv.Logins() {
+		t.Error("session file not reused")
+	}
+
+	if err := ioutil.WriteFile(e.env[envConfig], []byte("usr: typo\n"), 0600); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if code, _, stderr := e.run("domains"); exitError != code || !strings.Contains(stderr, "parse config") {
+		t.Errorf("expect config error, got %d %s", code, stderr)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	e := newTestEnv(t)
+	e.env[envUser] = testUser
+	e.env[envPassword] = testPwd
+
+	e.mustRun("records", "add", testDomain, "-name", "www", "-value", "10.0.0.2", "-ttl", "600")
+	e.mustRun("records", "add", "-type", "MX", "-value", "mail.example.com", "-priority", "10", testDomain)
+
+	var records []*recordView
+	if err := json.Unmarshal([]byte(e.mustRun("-o", "json", "records", "list", testDomain)), &records); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 3 != len(records) || "WWW" != strings.ToUpper(records[1].Name) || 600 != records[1].TTL || 10 != records[2].Priority {
+		t.Fatalf("unexpected records %+v", records)
+	}
+
+	e.mustRun("records", "modify", testDomain, "1", "-value", "10.0.0.3")
+	if out := e.mustRun("-o", "yaml", "records", "list", testDomain); !strings.Contains(out, "value: 10.0.0.3") || !strings.Contains(out, "ttl: 600") {
+		t.Errorf("unexpected yaml output %q", out)
+	}
+
+	e.mustRun("records", "delete", testDomain, "0")
+	out := e.mustRun("records", "list", testDomain)
+	if strings.Contains(out, "10.0.0.1") || !strings.HasPrefix(out, "#") {
+		t.Errorf("unexpected table output %q", out)
+	}
+
+	if code, _, _ := e.run("records", "delete", testDomain, "9"); exitError != code {
+		t.Errorf("expect error for missing record, got %d", code)
+	}
+
+	if code, _, stderr := e.run("records", "add", testDomain); exitUsage != code || !strings.Contains(stderr, "-value") {
+		t.Errorf("expect usage, got %d %s", code, stderr)
+	}
+}
+
+func TestRenewAndCheck(t *testing.T) {
+	e := newTestEnv(t)
+	e.env[envUser] = testUser
+	e.env[envPassword] = testPwd
+	e.srv.Take("freenom-cli.ml")
+
+	var planned []*renewView
+	if err := json.Unmarshal([]byte(e.mustRun("-o", "json", "renew", "-dry-run", "-months", "3")), &planned); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 1 != len(planned) || freenom.RenewalPlanned != planned[0].Status || 3 != planned[0].Months {
+		t.Errorf("unexpected plan output %+v", planned)
+	}
+
+	// -dry-run 没有续期，域名仍可续期
+	var renewed []*renewView
+	if err := json.Unmarshal([]byte(e.mustRun("-o", "json", "renew", "-months", "3")), &renewed); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 1 != len(renewed) || testDomain != renewed[0].Domain || freenom.RenewalSuccess != renewed[0].Status || 3 != renewed[0].Months {
+		t.Errorf("unexpected renew output %+v", renewed)
+	}
+
+	var checked []*availabilityView
+	if err := json.Unmarshal([]byte(e.mustRun("-o", "json", "check", "-tld", ".tk,.ml", "-rps", "0", "freenom-cli")), &checked); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 2 != len(checked) || !checked[0].Available || checked[1].Available {
+		t.Errorf("unexpected check output %+v", checked)
+	}
+
+	if code, _, stderr := e.run("check", "-rps", "0", "freenom-cli", "bad.prefix"); exitError != code || !strings.Contains(stderr, "bad.prefix") {
+		t.Errorf("expect partial failure, got %d %s", code, stderr)
+	}
+}
+
+func TestCart(t *testing.T) {
+	e := newTestEnv(t)
+	e.env[envUser] = testUser
+	e.env[envPassword] = testPwd
+
+	e.mustRun("cart", "add", "freenom-cli.tk")
+	e.mustRun("cart", "period", "freenom-cli.tk", "12")
+
+	var cart cartView
+	if err := json.Unmarshal([]byte(e.mustRun("-o", "json", "cart", "list")), &cart); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 1 != len(cart.Items) || 12 != cart.Items[0].Months || "0.00 USD" != cart.Total {
+		t.Errorf("unexpected cart %+v", cart)
+	}
+
+	e.mustRun("cart", "empty")
+	if out := e.mustRun("-o", "json", "cart", "list"); !strings.Contains(out, `"items": []`) {
+		t.Errorf("expect empty cart, got %q", out)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	e := newTestEnv(t)
+
+	if code, _, stderr := e.run("nosuch"); exitUsage != code || !strings.Contains(stderr, "records list") || !strings.Contains(stderr, "domains list") {
+		t.Errorf("expect usage, got %d %s", code, stderr)
+	}
+
+	if code, _, _ := e.run("-o", "xml", "domains"); exitUsage != code {
+		t.Errorf("expect usage for unknown format, got %d", code)
+	}
+
+	if code, _, stderr := e.run("records", "list"); exitUsage != code || !strings.Contains(stderr, "freenom records list <domain>") {
+		t.Errorf("expect command usage, got %d %s", code, stderr)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 输出格式
+const (
+	formatTable string = "table"
+	formatJSON  string = "json"
+	formatYAML  string = "yaml"
+)
+
+// table 以表格输出时的内容
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// addRow 添加一行
+func (t *table) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// printer 按输出格式输出命令的结果
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+// newPrinter 创建输出格式为 format 的 printer，format 为空时以表格输出
+func newPrinter(format string, w io.Writer) (p *printer, err error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		format = formatTable
+
+	case formatTable, formatJSON, formatYAML:
+
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expect table, json or yaml", format)
+	}
+
+	return &printer{
+		format: format,
+		w:      w,
+	}, nil
+}
+
+// newHelpWriter 创建输出帮助信息时对齐各列的 tabwriter
+func newHelpWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+}
+
+// print 输出结果，以表格输出时使用 t，否则将 v 编码为 JSON 或 YAML
+func (p *printer) print(v interface{}, t *table) (err error) {
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+
+	case formatYAML:
+		var all []byte
+		if all, err = yaml.Marshal(v); nil != err {
+			return
+		}
+
+		_, err = p.w.Write(all)
+		return
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	if 0 != len(t.header) {
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	}
+
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
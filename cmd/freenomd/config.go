@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 默认设置
+const (
+	defaultInterval time.Duration = time.Hour * 24 // 每天检查一遍所有域名是否可续期
+	defaultBackoff  time.Duration = time.Minute    // 登录失败后第一次重试的等待时间，之后每次翻倍
+	defaultMonths   int           = 12
+)
+
+// config 守护进程的配置文件
+type config struct {
+	Interval   time.Duration    `yaml:"interval"`    // 每个账号的检查间隔，默认 24h
+	Jitter     time.Duration    `yaml:"jitter"`      // 检查间隔的随机偏差，默认为 interval 的 1/24，为负数时不加偏差
+	Backoff    time.Duration    `yaml:"backoff"`     // 登录或读取域名失败后第一次重试的等待时间，默认 1m，之后每次翻倍，最多等待 interval
+	State      string           `yaml:"state"`       // 状态文件路径，为空时不保存状态
+	SessionDir string           `yaml:"session_dir"` // 保存各账号会话的目录，为空时每次启动都重新登录
+	BaseURL    string           `yaml:"base_url"`    // Freenom 站点地址，为空时使用默认地址
+	Accounts   []*accountConfig `yaml:"accounts"`
+}
+
+// accountConfig 一个账号及其续期策略
+type accountConfig struct {
+	User        string                   `yaml:"user"`
+	Password    string                   `yaml:"password"`
+	PasswordEnv string                   `yaml:"password_env"` // 从该环境变量读取密码，避免把密码写在配置文件中
+	Months      int                      `yaml:"months"`       // 每次续期的月份数，默认 12
+	OnlyListed  bool                     `yaml:"only_listed"`  // 只续期 domains 中列出的域名
+	Domains     map[string]*domainPolicy `yaml:"domains"`      // 单个域名的续期策略，键为完整域名
+}
+
+// domainPolicy 单个域名的续期策略
+type domainPolicy struct {
+	Months int  `yaml:"months"` // 每次续期的月份数，为 0 时使用账号的设置
+	Skip   bool `yaml:"skip"`   // 不续期该域名
+}
+
+// loadConfig 读取并校验 YAML 格式的配置文件，getenv 用于读取 password_env 指定的环境变量
+func loadConfig(path string, getenv func(string) string) (conf *config, err error) {
+	var all []byte
+	if all, err = ioutil.ReadFile(path); nil != err {
+		return nil, fmt.Errorf("read config err: %w", err)
+	}
+
+	conf = &config{}
+	if err = yaml.UnmarshalStrict(all, conf); nil != err {
+		return nil, fmt.Errorf("parse config %s err: %w", path, err)
+	}
+
+	if err = conf.init(getenv); nil != err {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	return
+}
+
+// init 填充默认值并校验配置
+func (conf *config) init(getenv func(string) string) (err error) {
+	if 0 == conf.Interval {
+		conf.Interval = defaultInterval
+	}
+
+	if 0 == conf.Jitter {
+		conf.Jitter = conf.Interval / 24 // 默认间隔时为 1h，间隔较短时随之减小
+	} else if conf.Jitter < 0 {
+		conf.Jitter = 0
+	}
+
+	if 0 == conf.Backoff {
+		conf.Backoff = defaultBackoff
+	}
+
+	if conf.Interval < 0 || conf.Backoff < 0 {
+		return errors.New("interval and backoff must be positive")
+	}
+
+	if conf.Jitter >= conf.Interval {
+		return fmt.Errorf("jitter %v must be less than interval %v", conf.Jitter, conf.Interval)
+	}
+
+	if 0 == len(conf.Accounts) {
+		return errors.New("no accounts")
+	}
+
+	users := make(map[string]bool)
+	for i, acc := range conf.Accounts {
+		if nil == acc || "" == acc.User {
+			return fmt.Errorf("account %d: user not set", i+1)
+		}
+
+		acc.User = strings.TrimSpace(acc.User)
+		key := strings.ToLower(acc.User)
+		if users[key] {
+			return fmt.Errorf("account %s: duplicated", acc.User)
+		}
+		users[key] = true
+
+		if "" != acc.PasswordEnv {
+			acc.Password = getenv(acc.PasswordEnv)
+		}
+
+		if "" == acc.Password {
+			return fmt.Errorf("account %s: password not set", acc.User)
+		}
+
+		if 0 == acc.Months {
+			acc.Months = defaultMonths
+		}
+
+		if acc.Months < 1 || acc.Months > 12 {
+			return fmt.Errorf("account %s: months must be between 1 and 12", acc.User)
+		}
+
+		// 域名统一转为小写
+		domains := make(map[string]*domainPolicy, len(acc.Domains))
+		for name, policy := range acc.Domains {
+			if nil == policy {
+				policy = &domainPolicy{}
+			}
+
+			if policy.Months < 0 || policy.Months > 12 {
+				return fmt.Errorf("account %s: domain %s: months must be between 1 and 12", acc.User, name)
+			}
+
+			domains[strings.ToLower(strings.TrimSpace(name))] = policy
+		}
+		acc.Domains = domains
+	}
+
+	return
+}
+
+// renewMonths 域名的续期月份数
+// 返回 月份数，不需要续期时 ok 为 false
+func (acc *accountConfig) renewMonths(domain string) (months int, ok bool) {
+	policy, listed := acc.Domains[strings.ToLower(domain)]
+	if !listed {
+		return acc.Months, !acc.OnlyListed
+	}
+
+	if policy.Skip {
+		return 0, false
+	}
+
+	if 0 == policy.Months {
+		return acc.Months, true
+	}
+
+	return policy.Months, true
+}
+
+// sessionFile 账号的会话文件路径，没有设置 session_dir 时返回空字符串
+func (conf *config) sessionFile(user string) string {
+	if "" == conf.SessionDir {
+		return ""
+	}
+
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', strings.ContainsRune("@._-", r):
+			return r
+		}
+
+		return '_'
+	}, strings.ToLower(user))
+
+	return filepath.Join(conf.SessionDir, name+".json")
+}
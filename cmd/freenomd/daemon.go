@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom"
+)
+
+const policySkipped string = "skipped by policy" // 可以续期但续期策略不允许时记录的原因
+
+// accountClient 账号使用的客户端，重新加载配置后密码或站点地址变化时重新创建
+type accountClient struct {
+	client   *freenom.Client
+	password string
+	baseURL  string
+}
+
+// daemon 按配置定期检查各账号的域名并续期
+type daemon struct {
+	conf    *config
+	state   *state
+	logger  *log.Logger
+	now     func() time.Time
+	rand    *rand.Rand
+	clients map[string]*accountClient // 键为小写的账号
+	dryRun  bool                      // 只记录将会续期的域名，不续期也不保存状态
+}
+
+// newDaemon 读取状态文件并创建 daemon
+func newDaemon(conf *config, logger *log.Logger) (d *daemon, err error) {
+	d = &daemon{
+		logger:  logger,
+		now:     time.Now,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		clients: make(map[string]*accountClient),
+	}
+
+	if d.state, err = loadState(conf.State); nil != err {
+		return nil, err
+	}
+
+	d.setConfig(conf)
+	return
+}
+
+// setConfig 使用新的配置，已删除账号的客户端及状态随之删除
+func (d *daemon) setConfig(conf *config) {
+	users := make(map[string]bool, len(conf.Accounts))
+	for _, acc := range conf.Accounts {
+		users[strings.ToLower(acc.User)] = true
+	}
+
+	for user := range d.clients {
+		if !users[user] {
+			delete(d.clients, user)
+		}
+	}
+
+	for user := range d.state.Accounts {
+		if !users[user] {
+			delete(d.state.Accounts, user)
+		}
+	}
+
+	d.conf = conf
+}
+
+// client 账号使用的客户端
+func (d *daemon) client(acc *accountConfig) (c *freenom.Client, err error) {
+	key := strings.ToLower(acc.User)
+	if ac, ok := d.clients[key]; ok && ac.password == acc.Password && ac.baseURL == d.conf.BaseURL {
+		return ac.client, nil
+	}
+
+	opts := []freenom.Option{
+		freenom.WithCredentials(acc.User, acc.Password),
+	}
+
+	if "" != d.conf.BaseURL {
+		opts = append(opts, freenom.WithBaseURL(d.conf.BaseURL))
+	}
+
+	if c, err = freenom.NewClient(opts...); nil != err {
+		return
+	}
+
+	d.clients[key] = &accountClient{
+		client:   c,
+		password: acc.Password,
+		baseURL:  d.conf.BaseURL,
+	}
+
+	return
+}
+
+// next 下一个需要检查的账号及检查时间
+func (d *daemon) next() (acc *accountConfig, at time.Time) {
+	for _, a := range d.conf.Accounts {
+		t := d.state.account(a.User).NextRun
+		if nil == acc || t.Before(at) {
+			acc, at = a, t
+		}
+	}
+
+	return
+}
+
+// interval 加上随机偏差后的检查间隔
+func (d *daemon) interval() time.Duration {
+	i := d.conf.Interval
+	if d.conf.Jitter > 0 {
+		i += time.Duration(d.rand.Int63n(int64(d.conf.Jitter)*2+1)) - d.conf.Jitter
+	}
+
+	return i
+}
+
+// backoff 第 failures 次连续失败后的重试等待时间，从 backoff 开始每次翻倍，最多等待 interval
+func (d *daemon) backoff(failures int) time.Duration {
+	wait := d.conf.Backoff
+	for i := 1; i < failures && wait < d.conf.Interval; i++ {
+		wait *= 2
+	}
+
+	if wait > d.conf.Interval {
+		wait = d.conf.Interval
+	}
+
+	return wait
+}
+
+// run 按计划检查各账号直到 ctx 被取消
+// reload 收到信号时调用 load 重新加载配置，加载失败时继续使用原来的配置
+func (d *daemon) run(ctx context.Context, reload <-chan struct{}, load func() (*config, error)) {
+	for {
+		acc, at := d.next()
+
+		timer := time.NewTimer(at.Sub(d.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+
+		case <-reload:
+			timer.Stop()
+
+			conf, err := load()
+			if nil != err {
+				d.logger.Printf("reload config failed, keep the old one: %v", err)
+				continue
+			}
+
+			d.setConfig(conf)
+			d.logger.Printf("config reloaded, %d accounts", len(conf.Accounts))
+
+		case <-timer.C:
+			d.check(ctx, acc)
+		}
+	}
+}
+
+// runOnce 依次检查所有账号一次
+// 返回 遇到的第一个错误
+func (d *daemon) runOnce(ctx context.Context) (err error) {
+	for _, acc := range d.conf.Accounts {
+		if e := d.check(ctx, acc); nil != e && nil == err {
+			err = e
+		}
+	}
+
+	return
+}
+
+// check 检查账号并续期，成功后按检查间隔安排下一次检查，登录、读取域名列表或续期计划失败后按重试等待时间安排
+// 单个域名续期失败只记录在该域名的状态中，仍按检查间隔安排下一次检查，避免反复提交会被拒绝的续期
+// 返回 账号检查失败的原因，或有域名续期失败时的错误
+func (d *daemon) check(ctx context.Context, acc *accountConfig) (err error) {
+	as := d.state.account(acc.User)
+	now := d.now()
+
+	var failed int
+	failed, err = d.renew(ctx, acc, as)
+	if nil != ctx.Err() { // 正在退出，下次启动时重新检查
+		return ctx.Err()
+	}
+
+	if d.dryRun {
+		if nil != err {
+			d.logger.Printf("%s: %v", acc.User, err)
+		}
+
+		return
+	}
+
+	as.LastRun = now
+	if nil != err {
+		as.Failures++
+		as.LastError = err.Error()
+		as.NextRun = now.Add(d.backoff(as.Failures))
+		d.logger.Printf("%s: %v, retry at %s", acc.User, err, as.NextRun.Format(time.RFC3339))
+	} else {
+		as.Failures = 0
+		as.LastError = ""
+		as.NextRun = now.Add(d.interval())
+		if 0 != failed {
+			err = fmt.Errorf("%d renewals failed", failed)
+			d.logger.Printf("%s: checked, %v, next check at %s", acc.User, err, as.NextRun.Format(time.RFC3339))
+		} else {
+			d.logger.Printf("%s: checked, next check at %s", acc.User, as.NextRun.Format(time.RFC3339))
+		}
+	}
+
+	if e := d.state.save(d.conf.State); nil != e {
+		d.logger.Print(e)
+	}
+
+	return
+}
+
+// renew 登录账号，读取一次续期计划，续期其中可以续期且续期策略允许的免费域名
+// 可以续期的时间范围由 freenom 决定，某个域名续期失败不影响其它域名，失败原因记录在域名的状态中
+// 返回 续期失败的域名数，以及登录、读取域名列表或续期计划时的错误
+func (d *daemon) renew(ctx context.Context, acc *accountConfig, as *accountState) (failed int, err error) {
+	var c *freenom.Client
+	if c, err = d.client(acc); nil != err {
+		return
+	}
+
+	sessionFile := d.conf.sessionFile(acc.User)
+	if err = login(ctx, c, sessionFile); nil != err {
+		err = fmt.Errorf("login: %w", err)
+		return
+	}
+
+	var domains []*freenom.Domain
+	if domains, err = c.DomainsContext(ctx); nil != err {
+		err = fmt.Errorf("list domains: %w", err)
+		return
+	}
+
+	// 会话可能已自动重新登录，每次都保存最新的会话，保存失败不影响续期
+	if !d.dryRun {
+		if e := saveSession(c, sessionFile); nil != e {
+			d.logger.Printf("%s: save session: %v", acc.User, e)
+		}
+	}
+
+	seen := make(map[string]bool, len(domains))
+	for _, dom := range domains {
+		name := strings.ToLower(dom.Domain)
+		seen[name] = true
+
+		ds, ok := as.Domains[name]
+		if !ok {
+			ds = &domainState{}
+			as.Domains[name] = ds
+		}
+		ds.ExpDate = dom.ExpDate
+	}
+
+	for name := range as.Domains {
+		if !seen[name] {
+			delete(as.Domains, name)
+		}
+	}
+
+	var renewals []*freenom.Renewal
+	if renewals, err = c.PlanRenewFreeDomainContext(ctx, "", acc.Months); nil != err {
+		err = fmt.Errorf("plan renewals: %w", err)
+		return
+	}
+
+	// 按续期策略决定每个域名续期的月份数，-dry-run 时记录每个域名将会续期或跳过的原因
+	for _, r := range renewals {
+		reason := ""
+		switch r.Status {
+		case freenom.RenewalNotDue:
+			reason = "not due for renewal yet"
+
+		case freenom.RenewalNotSelected:
+			reason = "not selected"
+
+		case freenom.RenewalPlanned:
+			if months, ok := acc.renewMonths(r.Domain); ok {
+				r.Months = months
+			} else {
+				r.Status = freenom.RenewalNotSelected
+				r.Months = 0
+				reason = policySkipped
+			}
+		}
+
+		switch {
+		case freenom.RenewalPlanned == r.Status:
+			if d.dryRun {
+				d.logger.Printf("%s: would renew %s for %d months, %d days left", acc.User, r.Domain, r.Months, r.DaysLeft)
+			}
+
+		case d.dryRun:
+			d.logger.Printf("%s: would skip %s (%s): %s, %d days left", acc.User, r.Domain, r.Status, reason, r.DaysLeft)
+
+		case policySkipped == reason: // 到期前每次检查都提醒
+			d.logger.Printf("%s: skip %s (%s): %s, %d days left", acc.User, r.Domain, r.Status, reason, r.DaysLeft)
+		}
+	}
+
+	if d.dryRun {
+		return
+	}
+
+	// 请求出错时中止提交，没有提交的域名同样记为失败，下次检查时再续期
+	if e := c.RenewPlannedContext(ctx, renewals); nil != e {
+		for _, r := range renewals {
+			if freenom.RenewalPlanned == r.Status {
+				r.Status = freenom.RenewalFailed
+				r.Err = fmt.Errorf("not submitted: %w", e)
+			}
+		}
+	}
+
+	now := d.now()
+	for _, r := range renewals {
+		if freenom.RenewalSuccess != r.Status && freenom.RenewalFailed != r.Status {
+			continue
+		}
+
+		name := strings.ToLower(r.Domain)
+		ds, ok := as.Domains[name]
+		if !ok {
+			ds = &domainState{}
+			as.Domains[name] = ds
+		}
+
+		ds.Months = r.Months
+		ds.RenewedAt = now
+		ds.Result = r.Status
+		ds.OrderNumber = r.OrderNumber
+		ds.Error = ""
+
+		if freenom.RenewalFailed == r.Status {
+			ds.Error = r.Err.Error()
+			failed++
+			d.logger.Printf("%s: renew %s for %d months failed: %v", acc.User, r.Domain, r.Months, r.Err)
+			continue
+		}
+
+		d.logger.Printf("%s: renew %s for %d months: %s %s", acc.User, r.Domain, r.Months, r.Status, r.OrderNumber)
+	}
+
+	return
+}
+
+// login 没有登录时从会话文件恢复会话或使用账号密码登录
+// 已登录的会话过期时客户端会自动重新登录
+func login(ctx context.Context, c *freenom.Client, sessionFile string) (err error) {
+	if _, err = c.Session(); nil == err {
+		return
+	}
+
+	if "" == sessionFile {
+		return c.LoginContext(ctx)
+	}
+
+	return c.RestoreSessionContext(ctx, sessionFile)
+}
+
+// saveSession 将会话保存到会话文件，sessionFile 为空时不保存
+func saveSession(c *freenom.Client, sessionFile string) (err error) {
+	if "" == sessionFile {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(sessionFile), 0700); nil != err {
+		return
+	}
+
+	return c.SaveSessionFile(sessionFile)
+}
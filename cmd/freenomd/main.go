@@ -0,0 +1,139 @@
+/*
+freenomd 是定期续期 Freenom 免费域名的守护进程
+
+用法：
+
+	freenomd [-config file] [-once] [-dry-run]
+
+配置文件为 YAML 格式，默认为用户配置目录下的 freenom/freenomd.yaml，例如：
+
+	interval: 24h            # 每个账号的检查间隔
+	jitter: 1h               # 检查间隔的随机偏差
+	backoff: 1m              # 登录或读取域名失败后第一次重试的等待时间，之后每次翻倍，最多等待 interval
+	state: /var/lib/freenomd/state.json
+	session_dir: /var/lib/freenomd/sessions
+	accounts:
+	  - user: user@example.com
+	    password_env: FREENOM_PASSWORD
+	    months: 12
+	    domains:
+	      example.tk: {months: 3}
+	      old.ml: {skip: true}
+
+每个账号按检查间隔登录并续期 14 天内到期的免费域名，登录或续期失败后按指数退避重试。
+各账号最近一次检查的结果及下一次检查的时间保存在状态文件中，重启后据此继续调度。
+
+使用 -dry-run 时检查所有账号一次，只在日志中列出可续期域名列表中每个域名将会续期的月份数，
+或跳过的状态及原因（还不能续期、续期策略不允许等），不续期也不保存状态。
+
+收到 SIGHUP 时重新加载配置文件，配置有误时继续使用原来的配置；
+收到 SIGTERM 或 SIGINT 时中止正在进行的请求，保存状态后退出。
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// 退出码
+const (
+	exitOK    int = 0
+	exitError int = 1
+	exitUsage int = 2
+)
+
+func main() {
+	ctx, stop := context.WithCancel(context.Background())
+	reload := make(chan struct{}, 1)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGHUP, os.Interrupt)
+	go func() {
+		for s := range sig {
+			if syscall.SIGHUP != s {
+				stop()
+				return
+			}
+
+			select {
+			case reload <- struct{}{}:
+			default: // 上一次重新加载还没有处理
+			}
+		}
+	}()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stderr, os.Getenv, reload))
+}
+
+// run 解析参数并运行守护进程，直到 ctx 被取消
+// 返回 退出码
+func run(ctx context.Context, args []string, stderr io.Writer, getenv func(string) string, reload <-chan struct{}) int {
+	logger := log.New(stderr, "freenomd: ", log.LstdFlags)
+
+	defaultConfig := ""
+	if dir, err := os.UserConfigDir(); nil == err {
+		defaultConfig = filepath.Join(dir, "freenom", "freenomd.yaml")
+	}
+
+	fs := flag.NewFlagSet("freenomd", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", defaultConfig, "config file")
+	once := fs.Bool("once", false, "check every account once and exit")
+	dryRun := fs.Bool("dry-run", false, "check every account once, log what would be renewed and exit without renewing or saving state")
+	if err := fs.Parse(args); nil != err {
+		if flag.ErrHelp == err {
+			return exitOK
+		}
+
+		return exitUsage
+	}
+
+	if 0 != fs.NArg() {
+		fs.Usage()
+		return exitUsage
+	}
+
+	load := func() (*config, error) {
+		return loadConfig(*configPath, getenv)
+	}
+
+	conf, err := load()
+	if nil != err {
+		logger.Print(err)
+		return exitError
+	}
+
+	d, err := newDaemon(conf, logger)
+	if nil != err {
+		logger.Print(err)
+		return exitError
+	}
+
+	if *once || *dryRun {
+		d.dryRun = *dryRun
+		if err = d.runOnce(ctx); nil != err {
+			return exitError
+		}
+
+		return exitOK
+	}
+
+	logger.Printf("started, %d accounts", len(conf.Accounts))
+	d.run(ctx, reload, load)
+
+	// 中止的检查没有保存状态
+	if err = d.state.save(d.conf.State); nil != err {
+		logger.Print(err)
+		return exitError
+	}
+
+	logger.Print("stopped")
+	return exitOK
+}
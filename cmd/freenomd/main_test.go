@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom"
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
+)
+
+const (
+	testUser string = "freenomapi@gmail.com"
+	testPwd  string = "AaBbCc!1@2#3"
+)
+
+// tempDir 创建测试结束后删除的临时目录
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "freenomd")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+// writeConfig 写入配置文件
+func writeConfig(t *testing.T, path, conf string) {
+	if err := ioutil.WriteFile(path, []byte(conf), 0600); nil != err {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestConfig(t *testing.T) {
+	dir := tempDir(t)
+	path := filepath.Join(dir, "freenomd.yaml")
+	getenv := func(k string) string {
+		if "TEST_PWD" == k {
+			return "from env"
+		}
+
+		return ""
+	}
+
+	writeConfig(t, path, `
+interval: 12h
+jitter: -1s
+accounts:
+  - user: a@example.com
+    password_env: TEST_PWD
+    months: 6
+    domains:
+      Short.TK: {months: 3}
+      keep.ml: {skip: true}
+      default.ga:
+  - user: b@example.com
+    password: secret
+    only_listed: true
+    domains:
+      listed.tk: {}
+`)
+
+	conf, err := loadConfig(path, getenv)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 12*time.Hour != conf.Interval || 0 != conf.Jitter || defaultBackoff != conf.Backoff {
+		t.Errorf("unexpected defaults %+v", conf)
+	}
+
+	a, b := conf.Accounts[0], conf.Accounts[1]
+	if "from env" != a.Password || defaultMonths != b.Months {
+		t.Errorf("unexpected accounts %+v %+v", a, b)
+	}
+
+	for _, tc := range []struct {
+		acc    *accountConfig
+		domain string
+		months int
+		ok     bool
+	}{
+		{a, "short.tk", 3, true},
+		{a, "keep.ml", 0, false},
+		{a, "default.ga", 6, true},
+		{a, "other.cf", 6, true},
+		{b, "listed.tk", 12, true},
+		{b, "other.cf", 12, false},
+	} {
+		if months, ok := tc.acc.renewMonths(tc.domain); tc.months != months || tc.ok != ok {
+			t.Errorf("%s %s: expect %d %v, got %d %v", tc.acc.User, tc.domain, tc.months, tc.ok, months, ok)
+		}
+	}
+
+	// 没有设置 jitter 时按检查间隔计算，不会因为间隔较短而出错
+	writeConfig(t, path, "interval: 1h\naccounts: [{user: a, password: b}]")
+	if conf, err = loadConfig(path, getenv); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if time.Hour/24 != conf.Jitter {
+		t.Errorf("expect jitter %v, got %v", time.Hour/24, conf.Jitter)
+	}
+
+	for _, bad := range []string{
+		"accounts: []",
+		"interval: 1h\njitter: 2h\naccounts: [{user: a, password: b}]",
+		"accounts: [{user: a}]",
+		"accounts: [{user: a, password: b, months: 13}]",
+		"accounts: [{user: a, password: b}, {user: A, password: c}]",
+		"accounts: [{user: a, password: b, domains: {x.tk: {months: 24}}}]",
+		"acounts: [{user: a, password: b}]",
+	} {
+		writeConfig(t, path, bad)
+		if _, err = loadConfig(path, getenv); nil == err {
+			t.Errorf("expect error for %q", bad)
+		}
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	srv := freenomtest.NewServer()
+	defer srv.Close()
+
+	srv.AddAccount(testUser, testPwd)
+	srv.AddAccount("other@example.com", "secret")
+
+	now := time.Now()
+	for name, days := range map[string]int{
+		"due.tk":   10,
+		"short.ml": 5,
+		"keep.ga":  3,
+		"later.cf": 100,
+	} {
+		srv.AddDomain(testUser, freenomtest.Domain{
+			Name:    name,
+			RegDate: now.AddDate(-1, 0, 0),
+			ExpDate: now.AddDate(0, 0, days),
+		})
+	}
+
+	dir := tempDir(t)
+	path := filepath.Join(dir, "freenomd.yaml")
+	writeConfig(t, path, `
+backoff: 5m
+state: `+filepath.Join(dir, "state.json")+`
+session_dir: `+filepath.Join(dir, "sessions")+`
+base_url: `+srv.URL+`
+accounts:
+  - user: `+testUser+`
+    password: "`+testPwd+`"
+    domains:
+      short.ml: {months: 3}
+      keep.ga: {skip: true}
+  - user: other@example.com
+    password: wrong
+`)
+
+	// -dry-run 只记录将会续期的域名
+	var logs bytes.Buffer
+	if code := run(context.Background(), []string{"-config", path, "-dry-run"}, &logs, os.Getenv, nil); exitError != code {
+		t.Fatalf("expect login failure of the second account, got %d\n%s", code, logs.String())
+	}
+
+	for _, want := range []string{
+		"would renew due.tk for 12 months",
+		"would renew short.ml for 3 months",
+		"would skip keep.ga (not_selected): skipped by policy",
+		"would skip later.cf (not_due): not due for renewal yet",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expect %q in logs:\n%s", want, logs.String())
+		}
+	}
+
+	if d, _ := srv.Domain(testUser, "due.tk"); d.ExpDate.After(now.AddDate(0, 0, 11)) {
+		t.Error("dry run renewed due.tk")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "state.json")); !os.IsNotExist(err) {
+		t.Errorf("dry run saved state: %v", err)
+	}
+
+	logs.Reset()
+	if code := run(context.Background(), []string{"-config", path, "-once"}, &logs, os.Getenv, nil); exitError != code {
+		t.Fatalf("expect login failure of the second account, got %d\n%s", code, logs.String())
+	}
+
+	if want := "skip keep.ga (not_selected): skipped by policy"; !strings.Contains(logs.String(), want) {
+		t.Errorf("expect %q in logs:\n%s", want, logs.String())
+	}
+
+	if strings.Contains(logs.String(), "later.cf") {
+		t.Errorf("domains not due should only be logged in dry run:\n%s", logs.String())
+	}
+
+	st, err := loadState(filepath.Join(dir, "state.json"))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	as := st.Accounts[testUser]
+	if nil == as || 0 != as.Failures || "" != as.LastError || !as.NextRun.After(as.LastRun.Add(defaultInterval-defaultInterval/24-time.Second)) {
+		t.Fatalf("unexpected state %+v", as)
+	}
+
+	for name, months := range map[string]int{"due.tk": 12, "short.ml": 3} {
+		if ds := as.Domains[name]; nil == ds || freenom.RenewalSuccess != ds.Result || months != ds.Months || "" == ds.OrderNumber {
+			t.Errorf("%s: unexpected state %+v", name, ds)
+		}
+
+		if d, _ := srv.Domain(testUser, name); d.ExpDate.Before(now.AddDate(0, months, 0)) {
+			t.Errorf("%s not renewed for %d months, expires at %v", name, months, d.ExpDate)
+		}
+	}
+
+	for _, name := range []string{"keep.ga", "later.cf"} {
+		if ds := as.Domains[name]; nil == ds || "" != ds.Result || ds.ExpDate.IsZero() {
+			t.Errorf("%s: unexpected state %+v", name, ds)
+		}
+	}
+
+	other := st.Accounts["other@example.com"]
+	if nil == other || 1 != other.Failures || !strings.Contains(other.LastError, "login") || !other.NextRun.Equal(other.LastRun.Add(5*time.Minute)) {
+		t.Errorf("unexpected state %+v", other)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "sessions", testUser+".json")); nil != err {
+		t.Errorf("session not saved: %v", err)
+	}
+
+	// 再次运行时复用会话文件，不再登录
+	logins := srv.Logins()
+	run(context.Background(), []string{"-config", path, "-once"}, &logs, os.Getenv, nil)
+	if logins != srv.Logins() {
+		t.Errorf("expect session reused, logins %d -> %d", logins, srv.Logins())
+	}
+}
+
+func TestRenewFailed(t *testing.T) {
+	srv := freenomtest.NewServer()
+	defer srv.Close()
+	srv.AddAccount(testUser, testPwd)
+
+	now := time.Now()
+	srv.AddDomain(testUser, freenomtest.Domain{
+		Name:       "rejected.tk",
+		RegDate:    now.AddDate(-1, 0, 0),
+		ExpDate:    now.AddDate(0, 0, 10),
+		RenewError: "This domain can not be renewed",
+	})
+	srv.AddDomain(testUser, freenomtest.Domain{
+		Name:    "due.ml",
+		RegDate: now.AddDate(-1, 0, 0),
+		ExpDate: now.AddDate(0, 0, 10),
+	})
+
+	dir := tempDir(t)
+	path := filepath.Join(dir, "freenomd.yaml")
+	writeConfig(t, path, `
+backoff: 5m
+state: `+filepath.Join(dir, "state.json")+`
+base_url: `+srv.URL+`
+accounts:
+  - user: `+testUser+`
+    password: "`+testPwd+`"
+`)
+
+	// 域名续期失败时 -once 返回错误，但不按重试等待时间安排下一次检查
+	var logs bytes.Buffer
+	if code := run(context.Background(), []string{"-config", path, "-once"}, &logs, os.Getenv, nil); exitError != code {
+		t.Fatalf("expect renewal failure, got %d\n%s", code, logs.String())
+	}
+
+	st, err := loadState(filepath.Join(dir, "state.json"))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	as := st.Accounts[testUser]
+	if nil == as || 0 != as.Failures || "" != as.LastError || !as.NextRun.After(as.LastRun.Add(defaultInterval-defaultInterval/24-time.Second)) {
+		t.Fatalf("unexpected state %+v", as)
+	}
+
+	if ds := as.Domains["rejected.tk"]; nil == ds || freenom.RenewalFailed != ds.Result || !strings.Contains(ds.Error, "can not be renewed") {
+		t.Errorf("rejected.tk: unexpected state %+v", ds)
+	}
+
+	if ds := as.Domains["due.ml"]; nil == ds || freenom.RenewalSuccess != ds.Result || "" != ds.Error {
+		t.Errorf("due.ml: unexpected state %+v", ds)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	srv := freenomtest.NewServer()
+	defer srv.Close()
+	srv.AddAccount(testUser, testPwd)
+
+	dir := tempDir(t)
+	path := filepath.Join(dir, "freenomd.yaml")
+	writeConfig(t, path, `
+interval: 1h
+jitter: 10m
+backoff: 1m
+base_url: `+srv.URL+`
+accounts:
+  - user: a@example.com
+    password: wrong
+  - user: `+testUser+`
+    password: "`+testPwd+`"
+`)
+
+	conf, err := loadConfig(path, os.Getenv)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	var logs bytes.Buffer
+	d, err := newDaemon(conf, log.New(&logs, "", 0))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	for failures, wait := range []time.Duration{time.Minute, time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		if wait != d.backoff(failures) {
+			t.Errorf("backoff(%d): expect %v, got %v", failures, wait, d.backoff(failures))
+		}
+	}
+
+	if time.Hour != d.backoff(10) {
+		t.Errorf("backoff should not exceed interval, got %v", d.backoff(10))
+	}
+
+	for i := 0; i < 100; i++ {
+		if i := d.interval(); i < 50*time.Minute || i > 70*time.Minute {
+			t.Fatalf("interval %v out of range", i)
+		}
+	}
+
+	// 运行中重新加载配置，删除密码错误的账号
+	// reload 没有缓冲，发送成功时上一次重新加载或检查已经完成
+	ctx, cancel := context.WithCancel(context.Background())
+	reload := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx, reload, func() (*config, error) { return loadConfig(path, os.Getenv) })
+		close(done)
+	}()
+
+	writeConfig(t, path, "accounts: [")
+	reload <- struct{}{}
+
+	writeConfig(t, path, `
+interval: 1h
+jitter: 10m
+base_url: `+srv.URL+`
+accounts:
+  - user: `+testUser+`
+    password: "`+testPwd+`"
+`)
+	reload <- struct{}{}
+	reload <- struct{}{}
+
+	cancel()
+	<-done
+
+	if 1 != len(d.conf.Accounts) || 1 != len(d.state.Accounts) {
+		t.Errorf("unexpected accounts after reload %+v", d.state.Accounts)
+	}
+
+	if !strings.Contains(logs.String(), "keep the old one") {
+		t.Errorf("expect reload error to be logged:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/internal/atomicfile"
+)
+
+// state 状态文件的内容，记录各账号最近一次检查的结果，重启后据此继续调度
+type state struct {
+	Accounts map[string]*accountState `json:"accounts"` // 键为小写的账号
+}
+
+// accountState 一个账号最近一次检查的结果
+type accountState struct {
+	LastRun   time.Time               `json:"last_run,omitempty"`   // 最近一次检查的时间
+	NextRun   time.Time               `json:"next_run,omitempty"`   // 下一次检查的时间
+	LastError string                  `json:"last_error,omitempty"` // 最近一次检查失败的原因，成功时为空
+	Failures  int                     `json:"failures,omitempty"`   // 连续失败的次数，用于计算重试等待时间
+	Domains   map[string]*domainState `json:"domains,omitempty"`    // 键为小写的完整域名
+}
+
+// domainState 一个域名最近一次检查的结果
+type domainState struct {
+	ExpDate     time.Time `json:"exp_date"`
+	Months      int       `json:"months,omitempty"`       // 最近一次续期的月份数
+	Result      string    `json:"result,omitempty"`       // 最近一次续期的状态，见 freenom.RenewalSuccess 等常量，没有续期过时为空
+	OrderNumber string    `json:"order_number,omitempty"` // 最近一次续期成功时的订单号
+	Error       string    `json:"error,omitempty"`        // 最近一次续期失败的原因
+	RenewedAt   time.Time `json:"renewed_at,omitempty"`   // 最近一次尝试续期的时间
+}
+
+// loadState 读取状态文件，文件不存在或 path 为空时返回空的状态
+func loadState(path string) (st *state, err error) {
+	st = &state{Accounts: make(map[string]*accountState)}
+	if "" == path {
+		return
+	}
+
+	var all []byte
+	all, err = ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	} else if nil != err {
+		return nil, fmt.Errorf("read state err: %w", err)
+	}
+
+	if err = json.Unmarshal(all, st); nil != err {
+		return nil, fmt.Errorf("parse state %s err: %w", path, err)
+	}
+
+	if nil == st.Accounts {
+		st.Accounts = make(map[string]*accountState)
+	}
+
+	return
+}
+
+// account 账号的状态，不存在时创建
+func (st *state) account(user string) *accountState {
+	key := strings.ToLower(user)
+	as, ok := st.Accounts[key]
+	if !ok {
+		as = &accountState{}
+		st.Accounts[key] = as
+	}
+
+	if nil == as.Domains {
+		as.Domains = make(map[string]*domainState)
+	}
+
+	return as
+}
+
+// save 先写入临时文件再替换，避免中途退出时状态文件损坏，path 为空时不保存
+func (st *state) save(path string) (err error) {
+	if "" == path {
+		return
+	}
+
+	var all []byte
+	if all, err = json.MarshalIndent(st, "", "  "); nil != err {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); nil != err {
+		return fmt.Errorf("save state err: %w", err)
+	}
+
+	return atomicfile.Write("save state", path, 0600, func(w io.Writer) (err error) {
+		_, err = w.Write(all)
+		return
+	})
+}
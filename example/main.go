@@ -22,19 +22,34 @@ const (
 )
 
 func main() {
-	var err error
+	client, err := freenom.NewClient(freenom.WithCredentials(freenomUser, freenomPwd))
+	if nil != err {
+		log.Println(err.Error())
+		return
+	}
+
+	// 登录账号，会话过期后客户端会自动重新登录
+	err = client.Login()
+	if nil != err {
+		log.Println(err.Error())
+		return
+	}
+
 	for {
-		// 登录账号
-		err = freenom.Login(freenomUser, freenomPwd)
+		// 开始续期
+		renewals, err := client.RenewFreeDomain("", renewMonths)
 		if nil != err {
 			log.Println(err.Error())
-			return
 		}
 
-		// 开始续期
-		_, err = freenom.RenewFreeDomain("", renewMonths)
-		if nil != err {
-			log.Println(err.Error())
+		for _, r := range renewals {
+			switch r.Status {
+			case freenom.RenewalSuccess:
+				log.Printf("%s renewed for %d months, order %s", r.Domain, r.Months, r.OrderNumber)
+
+			case freenom.RenewalFailed:
+				log.Printf("%s renew failed: %v", r.Domain, r.Err)
+			}
 		}
 
 		<-time.After(checkInterval)
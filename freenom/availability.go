@@ -0,0 +1,199 @@
+package freenom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 查询结果中的域名类型
+const (
+	DomainTypeFree    string = "FREE"    // 免费域名
+	DomainTypePaid    string = "PAID"    // 收费域名
+	DomainTypeSpecial string = "SPECIAL" // 免费后缀中需要付费的特殊域名，例如较短的域名
+)
+
+// 查询结果中的域名状态
+const (
+	DomainAvailable    string = "AVAILABLE"
+	DomainNotAvailable string = "NOT AVAILABLE"
+)
+
+// Availability 查询结果中的一个域名
+type Availability struct {
+	Domain string // 小写的完整域名，例如 freenom-api.tk
+	TLD    string // 域名后缀，例如 .tk
+	Status string // DomainAvailable 或 DomainNotAvailable，收费域名只有可注册时才会返回，均为 DomainAvailable
+	Type   string // DomainTypeFree、DomainTypePaid 或 DomainTypeSpecial
+	Price  Price  // 注册价格
+	InCart bool   // 是否已在当前会话的购物车中
+}
+
+// Available 是否可注册
+func (a *Availability) Available() bool {
+	return DomainAvailable == a.Status
+}
+
+// AvailabilityResult 域名查询结果
+type AvailabilityResult struct {
+	Domains        []*Availability // 按服务器返回的顺序，免费后缀在前，收费后缀在后
+	MaximumReached bool            // 购物车中的免费域名已达到上限，不能再加入
+	TopDomain      json.RawMessage // 服务器推荐的域名，原样保留，没有推荐时为 {"dont_show":1}
+}
+
+// Free 可免费注册的域名
+func (r *AvailabilityResult) Free() (domains []*Availability) {
+	for _, a := range r.Domains {
+		if a.Available() && DomainTypeFree == a.Type {
+			domains = append(domains, a)
+		}
+	}
+
+	return
+}
+
+// Domain 按完整域名查找查询结果，不存在时返回 nil
+func (r *AvailabilityResult) Domain(domain string) *Availability {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	for _, a := range r.Domains {
+		if domain == a.Domain {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// statusPrice 查询结果中的价格
+func statusPrice(s *DomainStatus) (p Price, err error) {
+	var units, cents int64
+	if units, err = strconv.ParseInt(strings.TrimSpace(s.PriceInt), 10, 64); nil != err {
+		err = fmt.Errorf("invalid price %q.%q: %w", s.PriceInt, s.PriceCent, ErrUnexpectedResponse)
+		return
+	}
+
+	if "" != s.PriceCent {
+		if cents, err = strconv.ParseInt(strings.TrimSpace(s.PriceCent), 10, 64); nil != err || cents < 0 || cents > 99 {
+			err = fmt.Errorf("invalid price %q.%q: %w", s.PriceInt, s.PriceCent, ErrUnexpectedResponse)
+			return
+		}
+	}
+
+	p.Cents = units*100 + cents
+	p.Currency = strings.ToUpper(s.Currency)
+	return
+}
+
+// availability 将 fn-available.php 返回的域名状态转换为查询结果
+// 参数 paid 是否为 paid_domains 中的收费域名，其状态及类型由服务器省略
+func availability(s *DomainStatus, paid bool) (a *Availability, err error) {
+	a = &Availability{
+		Domain: strings.ToLower(s.Domain + s.TLD),
+		TLD:    strings.ToLower(s.TLD),
+		Status: strings.ToUpper(s.Status),
+		Type:   strings.ToUpper(s.Type),
+		InCart: 0 != s.IsInCart,
+	}
+
+	if paid {
+		if "" == a.Status {
+			a.Status = DomainAvailable
+		}
+
+		if "" == a.Type {
+			a.Type = DomainTypePaid
+		}
+	}
+
+	a.Price, err = statusPrice(s)
+	return
+}
+
+// CheckAvailability 查询域名在各后缀下的注册状态及价格
+// 等同于使用 context.Background() 调用 CheckAvailabilityContext
+func (c *Client) CheckAvailability(domainPrefix string) (result *AvailabilityResult, err error) {
+	return c.CheckAvailabilityContext(context.Background(), domainPrefix)
+}
+
+// CheckAvailabilityContext 查询域名在各后缀下的注册状态及价格
+// 与 CheckFreeDomainPurchasable 不同，返回包括已被注册及收费后缀在内的所有结果
+// 已登录时使用当前会话查询，InCart 及 MaximumReached 反映当前会话的购物车
+// 参数 domainPrefix 不含后缀的域名，例如 freenom-api
+// ctx 被取消时中止请求及重试
+func (c *Client) CheckAvailabilityContext(ctx context.Context, domainPrefix string) (result *AvailabilityResult, err error) {
+	const op = "CheckAvailability"
+
+	var list *DomainListResult
+	list, err = c.checkAvailable(ctx, op, domainPrefix, "")
+	if nil != err {
+		return
+	}
+
+	result = &AvailabilityResult{
+		MaximumReached: 0 != list.MaximumReached,
+		TopDomain:      list.TopDomain,
+	}
+
+	for _, s := range list.FreeDomains {
+		var a *Availability
+		if a, err = availability(s, false); nil != err {
+			err = fmt.Errorf("%s %s%s %w", op, s.Domain, s.TLD, err)
+			return
+		}
+
+		result.Domains = append(result.Domains, a)
+	}
+
+	for _, s := range list.PaidDomains {
+		var a *Availability
+		if a, err = availability(s, true); nil != err {
+			err = fmt.Errorf("%s %s%s %w", op, s.Domain, s.TLD, err)
+			return
+		}
+
+		result.Domains = append(result.Domains, a)
+	}
+
+	return
+}
+
+// checkAvailable 调用 fn-available.php 查询域名
+// 已登录时使用当前会话，以便 is_in_cart 反映购物车，否则不带 cookie 查询
+// 参数 tld 为空时查询所有后缀
+func (c *Client) checkAvailable(ctx context.Context, op, prefix, tld string) (list *DomainListResult, err error) {
+	params := url.Values{}
+	params.Add("domain", prefix)
+	params.Add("tld", tld)
+
+	jar, _ := c.session()
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:         op,
+		method:       "POST",
+		path:         checkAvailablePath,
+		form:         params,
+		referer:      domainsPath,
+		noJar:        nil == jar,
+		noLoginCheck: true,
+	})
+	if nil != err {
+		return
+	}
+
+	list = &DomainListResult{}
+	if err = json.Unmarshal(all, list); nil != err {
+		err = fmt.Errorf("%s Unmarshal err: %w", op, err)
+		return
+	}
+
+	if !strings.EqualFold("OK", list.Status) {
+		err = fmt.Errorf("%s status %s err: %w", op, list.Status, ErrUnexpectedResponse)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,56 @@
+package freenom
+
+import (
+	"testing"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Take("freenom-api.ga")
+
+	c := DefaultClient()
+	result, err := c.CheckAvailability("freenom-api")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if result.MaximumReached || `{"dont_show":1}` != string(result.TopDomain) {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	a := result.Domain("freenom-api.ga")
+	if nil == a || a.Available() || DomainTypeFree != a.Type || !a.Price.IsFree() {
+		t.Errorf("unexpected taken domain %+v", a)
+	}
+
+	a = result.Domain("freenom-api.com")
+	if nil == a || !a.Available() || DomainTypePaid != a.Type || (Price{838, "USD"}) != a.Price {
+		t.Errorf("unexpected paid domain %+v", a)
+	}
+
+	// freenom-api.tk/.ml 已属于测试账号
+	free := result.Free()
+	if 2 != len(free) || "freenom-api.cf" != free[0].Domain || "freenom-api.gq" != free[1].Domain {
+		t.Errorf("unexpected free domains %+v", free)
+	}
+
+	if err = Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.AddToCart("freenom-api.cf"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if result, err = c.CheckAvailability("freenom-api"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if a = result.Domain("freenom-api.cf"); nil == a || !a.InCart {
+		t.Errorf("expect domain in cart, got %+v", a)
+	}
+
+	if a = result.Domain("freenom-api.gq"); nil == a || a.InCart {
+		t.Errorf("expect domain not in cart, got %+v", a)
+	}
+}
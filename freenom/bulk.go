@@ -0,0 +1,195 @@
+package freenom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultBulkWorkers int = 4
+
+// BulkCheckOptions 批量查询的设置
+type BulkCheckOptions struct {
+	Workers           int      // 同时查询的数量，小于等于 0 时为 4
+	RequestsPerSecond float64  // 每秒最多发起的查询次数，小于等于 0 时不限速，不包括失败后的重试
+	TLDs              []string // 只返回这些后缀的结果，例如 .tk 或 tk，为空时返回所有后缀
+}
+
+// BulkCheckResult 批量查询中一个域名前缀的结果
+type BulkCheckResult struct {
+	Prefix string              // 小写的域名前缀
+	Result *AvailabilityResult // 查询结果，Domains 已按 TLDs 过滤，出错时为 nil
+	Err    error               // 该前缀查询失败的原因，不影响其它前缀
+}
+
+// CheckAvailabilityBulk 批量查询多个域名前缀
+// 等同于使用 context.Background() 调用 CheckAvailabilityBulkContext
+func (c *Client) CheckAvailabilityBulk(prefixes []string, opts *BulkCheckOptions) <-chan *BulkCheckResult {
+	return c.CheckAvailabilityBulkContext(context.Background(), prefixes, opts)
+}
+
+// CheckAvailabilityBulkContext 批量查询多个域名前缀，由固定数量的协程并发调用 CheckAvailabilityContext
+// 前缀会转换为小写并去重，每个前缀在返回的通道中恰好有一个结果，按完成的先后顺序返回，全部完成后关闭通道
+// 不合法的前缀不会发起查询，直接以 ErrInvalidPrefix 作为结果返回
+// 调用方需要读完通道中的所有结果
+// 参数 opts 为 nil 时使用默认设置
+// ctx 被取消时尚未查询的前缀以 ctx.Err() 作为结果返回
+func (c *Client) CheckAvailabilityBulkContext(ctx context.Context, prefixes []string, opts *BulkCheckOptions) <-chan *BulkCheckResult {
+	if nil == opts {
+		opts = &BulkCheckOptions{}
+	}
+
+	jobs := make(chan string)
+	results := make(chan *BulkCheckResult)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+
+		seen := make(map[string]bool)
+		for _, p := range prefixes {
+			p = strings.ToLower(strings.TrimSpace(p))
+			if seen[p] {
+				continue
+			}
+
+			seen[p] = true
+			if !validLabel(p) { // 不占用限速配额
+				results <- &BulkCheckResult{
+					Prefix: p,
+					Err:    fmt.Errorf("CheckAvailabilityBulk %q: %w", p, ErrInvalidPrefix),
+				}
+				continue
+			}
+
+			jobs <- p
+		}
+	}()
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+
+	if workers > len(prefixes) {
+		workers = len(prefixes)
+	}
+
+	limit := newRateLimiter(opts.RequestsPerSecond)
+	tlds := bulkTLDs(opts.TLDs)
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for prefix := range jobs {
+				results <- c.bulkCheck(ctx, limit, prefix, tlds)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		limit.stop()
+		close(results)
+	}()
+
+	return results
+}
+
+// bulkCheck 等待限速后查询一个前缀，并按后缀过滤结果
+func (c *Client) bulkCheck(ctx context.Context, limit *rateLimiter, prefix string, tlds map[string]bool) (res *BulkCheckResult) {
+	res = &BulkCheckResult{
+		Prefix: prefix,
+	}
+
+	if res.Err = limit.wait(ctx); nil != res.Err {
+		return
+	}
+
+	res.Result, res.Err = c.CheckAvailabilityContext(ctx, prefix)
+	if nil != res.Err || 0 == len(tlds) {
+		return
+	}
+
+	domains := res.Result.Domains[:0]
+	for _, a := range res.Result.Domains {
+		if tlds[a.TLD] {
+			domains = append(domains, a)
+		}
+	}
+	res.Result.Domains = domains
+
+	return
+}
+
+// bulkTLDs 将后缀过滤条件统一为以 . 开头的小写形式
+func bulkTLDs(list []string) (tlds map[string]bool) {
+	tlds = make(map[string]bool)
+	for _, t := range list {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if "" == t {
+			continue
+		}
+
+		if !strings.HasPrefix(t, ".") {
+			t = "." + t
+		}
+
+		tlds[t] = true
+	}
+
+	return
+}
+
+// rateLimiter 按固定间隔放行请求的限速器，多个协程共用
+type rateLimiter struct {
+	ticker *time.Ticker // 不限速时为 nil
+}
+
+// newRateLimiter 创建每秒最多放行 rps 次的限速器，rps 小于等于 0 时不限速
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return &rateLimiter{}
+	}
+
+	interval := time.Duration(float64(time.Second) / rps)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+
+	return &rateLimiter{
+		ticker: time.NewTicker(interval),
+	}
+}
+
+// wait 等待下一次放行，ctx 被取消时返回 ctx.Err()
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); nil != err {
+		return err
+	}
+
+	if nil == l.ticker {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-l.ticker.C:
+		return nil
+	}
+}
+
+// stop 停止限速器
+func (l *rateLimiter) stop() {
+	if nil != l.ticker {
+		l.ticker.Stop()
+	}
+}
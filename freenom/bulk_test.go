@@ -0,0 +1,80 @@
+package freenom
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckAvailabilityBulk(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Take("freenom-b.tk")
+
+	prefixes := []string{"freenom-a", "freenom-b", "Freenom-A", "freenom-c", "freenom-d", "bad.prefix", "", "-foo", "with space"}
+
+	start := time.Now()
+	results := make(map[string]*BulkCheckResult)
+	for res := range DefaultClient().CheckAvailabilityBulk(prefixes, &BulkCheckOptions{
+		Workers:           2,
+		RequestsPerSecond: 50,
+		TLDs:              []string{"tk", ".COM"},
+	}) {
+		if _, ok := results[res.Prefix]; ok {
+			t.Errorf("duplicate result for %s", res.Prefix)
+		}
+
+		results[res.Prefix] = res
+	}
+
+	// 4 个合法的前缀，每 20ms 放行一次
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("rate limit not applied, took %s", elapsed)
+	}
+
+	if 8 != len(results) {
+		t.Fatalf("expect 8 results, got %d", len(results))
+	}
+
+	for _, bad := range []string{"bad.prefix", "", "-foo", "with space"} {
+		if res := results[bad]; nil == res || !errors.Is(res.Err, ErrInvalidPrefix) {
+			t.Errorf("%q: expect ErrInvalidPrefix, got %+v", bad, res)
+		}
+	}
+
+	res := results["freenom-b"]
+	if nil != res.Err {
+		t.Fatal(res.Err.Error())
+	}
+
+	if 2 != len(res.Result.Domains) {
+		t.Fatalf("expect .tk and .com, got %+v", res.Result.Domains)
+	}
+
+	if a := res.Result.Domain("freenom-b.tk"); nil == a || a.Available() {
+		t.Errorf("unexpected result %+v", a)
+	}
+
+	if a := res.Result.Domain("freenom-b.com"); nil == a || DomainTypePaid != a.Type {
+		t.Errorf("unexpected result %+v", a)
+	}
+}
+
+func TestCheckAvailabilityBulkCancel(t *testing.T) {
+	newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n := 0
+	for res := range DefaultClient().CheckAvailabilityBulkContext(ctx, []string{"freenom-a", "freenom-b", "freenom-c"}, nil) {
+		n++
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Errorf("expect context.Canceled for %s, got %v", res.Prefix, res.Err)
+		}
+	}
+
+	if 3 != n {
+		t.Errorf("expect 3 results, got %d", n)
+	}
+}
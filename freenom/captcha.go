@@ -0,0 +1,140 @@
+package freenom
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// reCAPTCHA v3 在购物车各页面上执行的动作
+const (
+	captchaActionConfDomains    string = "cart_confdomains_account"     // 域名配置页加载时
+	captchaActionCheckout       string = "cart_checkout_account"        // 购物车页加载时
+	captchaActionSubmitCheckout string = "cart_submit_checkout_account" // 提交结账表单时
+)
+
+// Captcha 需要求解的 Google reCAPTCHA v3
+type Captcha struct {
+	SiteKey string // 站点密钥，即页面中 api.js?render= 的参数
+	Action  string // grecaptcha.execute 使用的动作名称，例如 cart_confdomains_account
+	PageURL string // 执行 reCAPTCHA 的页面地址
+}
+
+// CaptchaSolver 求解 reCAPTCHA，返回提交给 Freenom 的令牌
+// 可以是提示用户在浏览器中手动获取令牌，也可以是调用第三方打码平台
+type CaptchaSolver interface {
+	SolveCaptcha(ctx context.Context, captcha *Captcha) (token string, err error)
+}
+
+// CaptchaSolverFunc 将普通函数用作 CaptchaSolver
+type CaptchaSolverFunc func(ctx context.Context, captcha *Captcha) (token string, err error)
+
+// SolveCaptcha 调用 f 求解 reCAPTCHA
+func (f CaptchaSolverFunc) SolveCaptcha(ctx context.Context, captcha *Captcha) (token string, err error) {
+	return f(ctx, captcha)
+}
+
+// PromptCaptchaSolver 在 w 中输出 reCAPTCHA 的参数，并从 r 中读取一行作为令牌
+// 适用于命令行中由用户在浏览器里执行 grecaptcha.execute 后粘贴令牌
+// 第一次求解时启动一个 goroutine 逐行读取 r，直到 r 出错或结束；
+// ctx 被取消时不会中止读取，之后读到的行留给下一次求解
+func PromptCaptchaSolver(r io.Reader, w io.Writer) CaptchaSolver {
+	type result struct {
+		line string
+		err  error
+	}
+
+	var once sync.Once
+	lines := make(chan result)
+	read := func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			lines <- result{line, err}
+			if nil != err {
+				close(lines)
+				return
+			}
+		}
+	}
+
+	return CaptchaSolverFunc(func(ctx context.Context, captcha *Captcha) (token string, err error) {
+		fmt.Fprintf(w, "reCAPTCHA site key: %s\naction: %s\npage: %s\ntoken: ",
+			captcha.SiteKey, captcha.Action, captcha.PageURL)
+
+		once.Do(func() { go read() })
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+
+		case res, ok := <-lines:
+			if !ok { // r 已经读完
+				res.err = io.EOF
+			}
+
+			token = strings.TrimSpace(res.line)
+			if "" == token {
+				if nil == res.err {
+					res.err = io.ErrUnexpectedEOF
+				}
+
+				return "", fmt.Errorf("PromptCaptchaSolver ReadString err: %w", res.err)
+			}
+
+			return token, nil
+		}
+	})
+}
+
+// solveCaptcha 使用客户端设置的 CaptchaSolver 求解 reCAPTCHA
+func (c *Client) solveCaptcha(ctx context.Context, op, siteKey, action, pagePath string) (token string, err error) {
+	c.mu.Lock()
+	solver := c.captchaSolver
+	c.mu.Unlock()
+
+	if nil == solver {
+		return "", fmt.Errorf("%s: %w", op, ErrNoCaptchaSolver)
+	}
+
+	if "" == siteKey {
+		return "", fmt.Errorf("%s captcha site key not found: %w", op, ErrUnexpectedResponse)
+	}
+
+	token, err = solver.SolveCaptcha(ctx, &Captcha{
+		SiteKey: siteKey,
+		Action:  action,
+		PageURL: c.url(pagePath, nil),
+	})
+	if nil != err {
+		return "", fmt.Errorf("%s SolveCaptcha %s err: %w", op, action, err)
+	}
+
+	return
+}
+
+// submitCaptcha 求解页面加载时执行的 reCAPTCHA 并提交到 cap_chk.php，与浏览器中页面加载完成后的行为一致
+func (c *Client) submitCaptcha(ctx context.Context, op, siteKey, action, pagePath string) (err error) {
+	var token string
+	token, err = c.solveCaptcha(ctx, op, siteKey, action, pagePath)
+	if nil != err {
+		return
+	}
+
+	params := url.Values{}
+	params.Add("token", token)
+
+	_, err = c.do(ctx, &request{
+		name:         op + " CaptchaCheck",
+		method:       "POST",
+		path:         captchaCheckPath,
+		form:         params,
+		referer:      pagePath,
+		noLoginCheck: true,
+	})
+	return
+}
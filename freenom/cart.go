@@ -0,0 +1,417 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+const removeFromCartPath string = "includes/domains/fn-remove.php"
+const emptyCartPath string = "cart.php?a=empty"
+
+// rePrice 页面上显示的价格，例如 $9.95USD、€1,234.50 EUR
+var rePrice = regexp.MustCompile(`([0-9][0-9,]*)(?:\.([0-9]{1,2}))?\s*([A-Za-z]{3})?`)
+
+// Price 金额，以分为单位保存以避免浮点误差
+type Price struct {
+	Cents    int64  // 金额，单位为分，例如 9.95 为 995
+	Currency string // 货币代码，例如 USD，页面上没有显示时为空
+}
+
+// String 以十进制金额及货币代码表示，例如 9.95 USD
+func (p Price) String() string {
+	s := fmt.Sprintf("%d.%02d", p.Cents/100, p.Cents%100)
+	if "" == p.Currency {
+		return s
+	}
+
+	return s + " " + p.Currency
+}
+
+// IsFree 是否免费
+func (p Price) IsFree() bool {
+	return 0 == p.Cents
+}
+
+// parsePrice 解析页面上显示的价格，空字符串返回零值
+func parsePrice(s string) (p Price, err error) {
+	s = strings.TrimSpace(s)
+	if "" == s {
+		return
+	}
+
+	match := rePrice.FindStringSubmatch(s)
+	if nil == match {
+		err = fmt.Errorf("invalid price %q: %w", s, ErrUnexpectedResponse)
+		return
+	}
+
+	var units int64
+	units, err = strconv.ParseInt(strings.Replace(match[1], ",", "", -1), 10, 64)
+	if nil != err {
+		err = fmt.Errorf("invalid price %q: %w", s, ErrUnexpectedResponse)
+		return
+	}
+
+	cents := match[2]
+	if 1 == len(cents) {
+		cents += "0"
+	}
+
+	p.Cents = units * 100
+	if "" != cents {
+		c, _ := strconv.ParseInt(cents, 10, 64)
+		p.Cents += c
+	}
+
+	p.Currency = strings.ToUpper(match[3])
+	return
+}
+
+// CartItem 购物车中的一项
+type CartItem struct {
+	Domain       string // 小写的完整域名，不是域名注册时为空
+	Description  string // 购物车页中的描述，例如 Domain Registration - freenom-api.tk
+	Months       int    // 注册月数，未知时为 0
+	Price        Price  // 实际价格
+	RegularPrice Price  // 优惠前的原价，没有优惠时与 Price 相同
+}
+
+// Cart 购物车
+type Cart struct {
+	Items    []*CartItem
+	Subtotal Price
+	Total    Price // 今天需要支付的金额
+}
+
+// Item 按域名查找购物车中的一项，不存在时返回 nil
+func (cart *Cart) Item(domain string) *CartItem {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	for _, item := range cart.Items {
+		if "" != item.Domain && domain == item.Domain {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// periodMonths 注册时长（例如 12M）对应的月数，格式不正确时返回 0
+func periodMonths(period string) int {
+	if !strings.HasSuffix(period, "M") {
+		return 0
+	}
+
+	m, err := strconv.Atoi(strings.TrimSuffix(period, "M"))
+	if nil != err || m < 0 {
+		return 0
+	}
+
+	return m
+}
+
+// cartDomain 检查并拆分要放入购物车的域名
+func cartDomain(op, domain string) (prefix, tld string, err error) {
+	var ok bool
+	prefix, tld, ok = splitDomain(domain)
+	if !ok {
+		err = fmt.Errorf("%s invalid domain %q: %w", op, domain, ErrDomainUnavailable)
+	}
+
+	return
+}
+
+// AddToCart 将域名加入购物车
+// 等同于使用 context.Background() 调用 AddToCartContext
+func (c *Client) AddToCart(domain string) (err error) {
+	return c.AddToCartContext(context.Background(), domain)
+}
+
+// AddToCartContext 将域名加入购物车，即查询结果中的 Get it now!
+// 加入后的注册时长为 Freenom 的默认值，可以通过 SetCartPeriod 修改
+// 购物车保存在当前会话中，可以之后在浏览器中导入同一会话完成结账
+// 域名不可注册时返回 ErrDomainUnavailable
+// ctx 被取消时中止请求及重试
+func (c *Client) AddToCartContext(ctx context.Context, domain string) (err error) {
+	const op = "AddToCart"
+
+	var prefix, tld string
+	prefix, tld, err = cartDomain(op, domain)
+	if nil != err {
+		return
+	}
+
+	return c.addToCart(ctx, op, prefix, tld)
+}
+
+// SetCartPeriod 修改购物车中域名的注册时长
+// 等同于使用 context.Background() 调用 SetCartPeriodContext
+func (c *Client) SetCartPeriod(domain string, months int) (err error) {
+	return c.SetCartPeriodContext(context.Background(), domain, months)
+}
+
+// SetCartPeriodContext 修改购物车中域名的注册时长
+// 参数 months 注册月数，1 至 12
+// ctx 被取消时中止请求及重试
+func (c *Client) SetCartPeriodContext(ctx context.Context, domain string, months int) (err error) {
+	const op = "SetCartPeriod"
+
+	if months < 1 || months > 12 {
+		return ErrInvalidPeriod
+	}
+
+	var prefix, tld string
+	prefix, tld, err = cartDomain(op, domain)
+	if nil != err {
+		return
+	}
+	domain = prefix + tld
+
+	params := url.Values{}
+	params.Add("domain", domain)
+	params.Add("period", fmt.Sprintf("%dM", months))
+
+	return c.updateCartDomain(ctx, op, domain, params)
+}
+
+// ConfigureCartDomain 设置购物车中域名的域名服务器或 URL 转发
+// 等同于使用 context.Background() 调用 ConfigureCartDomainContext
+func (c *Client) ConfigureCartDomain(domain string, opts *PurchaseOptions) (err error) {
+	return c.ConfigureCartDomainContext(context.Background(), domain, opts)
+}
+
+// ConfigureCartDomainContext 设置购物车中域名的域名服务器或 URL 转发
+// 参数 opts 与 PurchaseFreeDomain 相同，为 nil 时使用 Freenom DNS
+// ctx 被取消时中止请求及重试
+func (c *Client) ConfigureCartDomainContext(ctx context.Context, domain string, opts *PurchaseOptions) (err error) {
+	const op = "ConfigureCartDomain"
+
+	if nil == opts {
+		opts = &PurchaseOptions{}
+	}
+
+	var config map[string]string
+	config, err = purchaseConfig(op, opts)
+	if nil != err {
+		return
+	}
+
+	var prefix, tld string
+	prefix, tld, err = cartDomain(op, domain)
+	if nil != err {
+		return
+	}
+
+	return c.configureCartDomain(ctx, op, prefix+tld, config)
+}
+
+// Cart 列出购物车中的内容及价格
+// 等同于使用 context.Background() 调用 CartContext
+func (c *Client) Cart() (cart *Cart, err error) {
+	return c.CartContext(context.Background())
+}
+
+// CartContext 列出购物车中的内容及价格
+// 价格取自购物车页，购物车不为空时还会打开域名配置页获取各域名的注册时长
+// ctx 被取消时中止请求及重试
+func (c *Client) CartContext(ctx context.Context) (cart *Cart, err error) {
+	const op = "Cart"
+
+	var page *scrape.CartPage
+	page, err = c.cartPage(ctx, op, &request{
+		name:    op,
+		path:    cartViewPath,
+		referer: domainsPath,
+	})
+	if nil != err {
+		return
+	}
+
+	cart = &Cart{}
+	if cart.Subtotal, err = parsePrice(page.Subtotal); nil != err {
+		err = fmt.Errorf("%s subtotal %w", op, err)
+		return
+	}
+
+	if cart.Total, err = parsePrice(page.Total); nil != err {
+		err = fmt.Errorf("%s total %w", op, err)
+		return
+	}
+
+	for _, row := range page.Items {
+		item := &CartItem{
+			Domain:      row.Domain,
+			Description: row.Description,
+		}
+
+		if item.Price, err = parsePrice(row.Price); nil != err {
+			err = fmt.Errorf("%s %s %w", op, row.Description, err)
+			return
+		}
+
+		item.RegularPrice = item.Price
+		if "" != row.RegularPrice {
+			if item.RegularPrice, err = parsePrice(row.RegularPrice); nil != err {
+				err = fmt.Errorf("%s %s %w", op, row.Description, err)
+				return
+			}
+		}
+
+		cart.Items = append(cart.Items, item)
+	}
+
+	if 0 == len(cart.Items) {
+		return
+	}
+
+	var conf *scrape.ConfDomainsPage
+	conf, err = c.confDomains(ctx, op)
+	if nil != err {
+		return
+	}
+
+	for _, d := range conf.Domains {
+		if item := cart.Item(d.Domain); nil != item {
+			item.Months = periodMonths(d.Period)
+		}
+	}
+
+	return
+}
+
+// RemoveFromCart 从购物车中删除域名
+// 等同于使用 context.Background() 调用 RemoveFromCartContext
+func (c *Client) RemoveFromCart(domain string) (err error) {
+	return c.RemoveFromCartContext(context.Background(), domain)
+}
+
+// RemoveFromCartContext 从购物车中删除域名，即域名配置页中的删除按钮
+// 域名不在购物车中时返回 *ManageError
+// fn-remove.php 的响应内容为空，删除后重新读取购物车，域名仍在购物车中时返回 ErrUnexpectedResponse
+// ctx 被取消时中止请求及重试
+func (c *Client) RemoveFromCartContext(ctx context.Context, domain string) (err error) {
+	const op = "RemoveFromCart"
+
+	var prefix, tld string
+	prefix, tld, err = cartDomain(op, domain)
+	if nil != err {
+		return
+	}
+	domain = prefix + tld
+
+	var in bool
+	if in, err = c.inCart(ctx, op, domain); nil != err {
+		return
+	} else if !in {
+		return &ManageError{
+			Op:      op,
+			Domain:  domain,
+			Message: "domain not in cart",
+		}
+	}
+
+	params := url.Values{}
+	params.Add("domain", prefix)
+	params.Add("tld", tld)
+
+	_, err = c.do(ctx, &request{
+		name:         op,
+		method:       "POST",
+		path:         removeFromCartPath,
+		form:         params,
+		referer:      confDomainsPath,
+		noLoginCheck: true,
+	})
+	if nil != err {
+		return
+	}
+
+	if in, err = c.inCart(ctx, op, domain); nil != err {
+		return
+	} else if in {
+		return fmt.Errorf("%s %s still in cart: %w", op, domain, ErrUnexpectedResponse)
+	}
+
+	return
+}
+
+// inCart 读取购物车页，判断域名是否在购物车中
+func (c *Client) inCart(ctx context.Context, op, domain string) (in bool, err error) {
+	var page *scrape.CartPage
+	page, err = c.cartPage(ctx, op, &request{
+		name:    op + " Cart",
+		path:    cartViewPath,
+		referer: confDomainsPath,
+	})
+	if nil != err {
+		return
+	}
+
+	for _, row := range page.Items {
+		if strings.EqualFold(domain, row.Domain) {
+			return true, nil
+		}
+	}
+
+	return
+}
+
+// EmptyCart 清空购物车
+// 等同于使用 context.Background() 调用 EmptyCartContext
+func (c *Client) EmptyCart() (err error) {
+	return c.EmptyCartContext(context.Background())
+}
+
+// EmptyCartContext 清空购物车，即购物车页中的 Empty Cart
+// ctx 被取消时中止请求及重试
+func (c *Client) EmptyCartContext(ctx context.Context) (err error) {
+	const op = "EmptyCart"
+
+	var page *scrape.CartPage
+	page, err = c.cartPage(ctx, op, &request{
+		name:    op,
+		path:    emptyCartPath,
+		referer: cartViewPath,
+	})
+	if nil != err {
+		return
+	}
+
+	if 0 != len(page.Items) {
+		err = fmt.Errorf("%s %d items left: %w", op, len(page.Items), ErrUnexpectedResponse)
+		return
+	}
+
+	return
+}
+
+// cartPage 发送请求并解析返回的购物车页
+func (c *Client) cartPage(ctx context.Context, op string, r *request) (page *scrape.CartPage, err error) {
+	var all []byte
+	all, err = c.do(ctx, r)
+	if nil != err {
+		return
+	}
+
+	page, err = scrape.ParseCart(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("%s %w", op, err)
+		return
+	}
+
+	if 0 != len(page.Errors) {
+		err = &ManageError{
+			Op:      op,
+			Message: strings.Join(page.Errors, "; "),
+		}
+		return
+	}
+
+	return
+}
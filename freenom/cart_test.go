@@ -0,0 +1,173 @@
+package freenom
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParsePrice(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want Price
+	}{
+		{"", Price{}},
+		{"$0.00USD", Price{0, "USD"}},
+		{"$9.95USD", Price{995, "USD"}},
+		{"€1,234.5 eur", Price{123450, "EUR"}},
+		{"12", Price{1200, ""}},
+	} {
+		got, err := parsePrice(tc.in)
+		if nil != err {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+
+		if tc.want != got {
+			t.Errorf("%q: expect %+v, got %+v", tc.in, tc.want, got)
+		}
+	}
+
+	if _, err := parsePrice("free"); !errors.Is(err, ErrUnexpectedResponse) {
+		t.Errorf("expect ErrUnexpectedResponse, got %v", err)
+	}
+
+	if s := (Price{995, "USD"}).String(); "9.95 USD" != s {
+		t.Errorf("unexpected string %q", s)
+	}
+}
+
+func TestCart(t *testing.T) {
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	c := DefaultClient()
+	for _, domain := range []string{"Freenom-Cart.tk", "freenom-cart.ml"} {
+		if err := c.AddToCart(domain); nil != err {
+			t.Fatal(err.Error())
+		}
+	}
+
+	if err := c.AddToCart(freenomDomain); !errors.Is(err, ErrDomainUnavailable) {
+		t.Errorf("expect ErrDomainUnavailable, got %v", err)
+	}
+
+	if err := c.SetCartPeriod("freenom-cart.tk", 12); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err := c.SetCartPeriod("freenom-cart.tk", 13); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("expect ErrInvalidPeriod, got %v", err)
+	}
+
+	var me *ManageError
+	if err := c.SetCartPeriod("freenom-none.tk", 12); !errors.As(err, &me) {
+		t.Errorf("expect ManageError for domain not in cart, got %v", err)
+	}
+
+	if err := c.ConfigureCartDomain("freenom-cart.ml", &PurchaseOptions{ForwardURL: "https://example.com/"}); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err := c.ConfigureCartDomain("freenom-cart.ml", &PurchaseOptions{ForwardURL: "https://freenom-cart.ml/"}); !errors.As(err, &me) {
+		t.Errorf("expect ManageError, got %v", err)
+	}
+
+	if err := c.ConfigureCartDomain("freenom-cart.ml", &PurchaseOptions{Nameservers: []string{"ns1.example.com"}}); !errors.Is(err, ErrInvalidNameservers) {
+		t.Errorf("expect ErrInvalidNameservers, got %v", err)
+	}
+
+	cart, err := c.Cart()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 2 != len(cart.Items) {
+		t.Fatalf("expect 2 items, got %d", len(cart.Items))
+	}
+
+	item := cart.Item("FREENOM-CART.TK")
+	if nil == item || 12 != item.Months || !item.Price.IsFree() || 995 != item.RegularPrice.Cents {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	if item = cart.Item("freenom-cart.ml"); nil == item || 3 != item.Months {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	if !cart.Total.IsFree() || "USD" != cart.Total.Currency {
+		t.Errorf("unexpected total %s", cart.Total)
+	}
+
+	if err = c.RemoveFromCart("freenom-cart.tk"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if cart, err = c.Cart(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 1 != len(cart.Items) || nil == cart.Item("freenom-cart.ml") {
+		t.Errorf("unexpected items after remove %+v", cart.Items)
+	}
+
+	// 不在购物车中的域名
+	if err = c.RemoveFromCart("freenom-cart.tk"); !errors.As(err, &me) || "freenom-cart.tk" != me.Domain {
+		t.Errorf("expect ManageError, got %v", err)
+	}
+
+	if err = c.EmptyCart(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if cart, err = c.Cart(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 0 != len(cart.Items) {
+		t.Errorf("expect empty cart, got %+v", cart.Items)
+	}
+
+	if _, ok := srv.Domain(freenomUser, "freenom-cart.ml"); ok {
+		t.Error("domain registered without checkout")
+	}
+}
+
+func TestRemoveFromCartIgnored(t *testing.T) {
+	srv := newTestServer(t)
+
+	// 服务器没有删除域名时，fn-remove.php 的响应同样为空
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/"+removeFromCartPath) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		}
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	c, err := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd), WithTransport(rt))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.AddToCart("freenom-cart.tk"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.RemoveFromCart("freenom-cart.tk"); !errors.Is(err, ErrUnexpectedResponse) {
+		t.Errorf("expect ErrUnexpectedResponse, got %v", err)
+	}
+}
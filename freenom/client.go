@@ -0,0 +1,494 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+const defaultBaseURL string = "https://my.freenom.com/"
+
+// 所有客户端共用的默认 Transport，复用连接，不再为每个请求单独创建
+var defaultTransport http.RoundTripper = newDefaultTransport()
+
+// newDefaultTransport 在 http.DefaultTransport 的基础上跳过证书校验（与之前的行为保持一致）
+// 代理设置同样从环境变量 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 中读取
+func newDefaultTransport() *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: true,
+	}
+
+	return tr
+}
+
+// Client Freenom 客户端，每个客户端拥有独立的会话（cookie、令牌及域名缓存），
+// 因此同一进程内可以同时管理多个 Freenom 账号
+type Client struct {
+	user string
+	pwd  string
+
+	baseURL       *url.URL
+	httpClient    *http.Client
+	transport     http.RoundTripper
+	retryTimes    int
+	retryInterval time.Duration
+	autoRelogin   bool
+	captchaSolver CaptchaSolver
+
+	mu sync.Mutex
+
+	// 保证同一时间只有一个请求在重新登录
+	loginMu sync.Mutex
+
+	// cookie 容器
+	jar *cookiejar.Jar
+
+	// 会话令牌
+	token string
+
+	// 域名信息缓存表
+	domainInfoMap map[string]*DomainInfo
+}
+
+// Option 客户端配置项
+type Option func(c *Client) error
+
+// WithCredentials 设置登录使用的账号密码
+func WithCredentials(user, pwd string) Option {
+	return func(c *Client) error {
+		c.user = user
+		c.pwd = pwd
+		return nil
+	}
+}
+
+// WithBaseURL 设置 Freenom 站点地址，默认为 https://my.freenom.com/
+func WithBaseURL(rawURL string) Option {
+	return func(c *Client) (err error) {
+		var u *url.URL
+		u, err = url.Parse(rawURL)
+		if nil != err {
+			return fmt.Errorf("WithBaseURL Parse err: %s", err.Error())
+		}
+
+		if "" == u.Scheme || "" == u.Host {
+			return fmt.Errorf("WithBaseURL invalid url: %s", rawURL)
+		}
+
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+
+		c.baseURL = u
+		return nil
+	}
+}
+
+// WithHTTPClient 使用指定的 http.Client 发送请求
+// 客户端会复制一份并替换其中的 Jar，不会修改传入的对象
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		c.httpClient = hc
+		return nil
+	}
+}
+
+// WithTransport 使用指定的 http.RoundTripper 发送请求，例如录制代理或本地模拟服务
+// 与 WithHTTPClient 同时使用时，替换其中的 Transport
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) error {
+		c.transport = rt
+		return nil
+	}
+}
+
+// WithRetry 设置请求失败时的重试参数
+// 参数 times 最多尝试次数，最少 1 次
+// 参数 interval 两次尝试之间的等待时间
+func WithRetry(times int, interval time.Duration) Option {
+	return func(c *Client) error {
+		if times < 1 {
+			return fmt.Errorf("WithRetry times should be greater than 0")
+		}
+
+		c.retryTimes = times
+		c.retryInterval = interval
+		return nil
+	}
+}
+
+// WithAutoRelogin 设置会话过期时是否自动重新登录，默认开启
+// 开启时客户端发现返回的是未登录的页面后，会使用保存的账号密码重新登录并重放一次原请求
+func WithAutoRelogin(enable bool) Option {
+	return func(c *Client) error {
+		c.autoRelogin = enable
+		return nil
+	}
+}
+
+// WithCaptchaSolver 设置购买域名等需要通过 reCAPTCHA 校验的操作使用的 CaptchaSolver
+func WithCaptchaSolver(solver CaptchaSolver) Option {
+	return func(c *Client) error {
+		c.captchaSolver = solver
+		return nil
+	}
+}
+
+// NewClient 创建 Freenom 客户端
+func NewClient(opts ...Option) (c *Client, err error) {
+	c = &Client{
+		retryTimes:    retryTimes,
+		autoRelogin:   true,
+		domainInfoMap: make(map[string]*DomainInfo),
+	}
+
+	err = WithBaseURL(defaultBaseURL)(c)
+	if nil != err {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		if err = opt(c); nil != err {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// SetCredentials 修改登录使用的账号密码
+func (c *Client) SetCredentials(user, pwd string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.user = user
+	c.pwd = pwd
+}
+
+// SetCaptchaSolver 修改需要通过 reCAPTCHA 校验时使用的 CaptchaSolver
+func (c *Client) SetCaptchaSolver(solver CaptchaSolver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.captchaSolver = solver
+}
+
+// request 一次 HTTP 请求的描述
+type request struct {
+	name    string         // 错误信息前缀
+	method  string         // 默认为 GET
+	path    string         // 相对于站点地址的路径
+	query   url.Values     // URL 参数
+	form    url.Values     // POST 表单
+	referer string         // 相对于站点地址的来源页
+	jar     *cookiejar.Jar // 使用指定的 cookie 容器（登录过程中使用）
+	noJar   bool           // 不携带会话 cookie
+	noRetry bool           // 失败时不重试（非幂等操作）
+
+	noLoginCheck bool // 响应不是带有登录标记的页面（例如 JSON 接口），不检查会话是否过期
+}
+
+// url 拼接站点地址与相对路径，路径中可以带有 URL 参数
+func (c *Client) url(path string, query url.Values) string {
+	u := *c.baseURL
+	if i := strings.Index(path, "?"); i >= 0 {
+		u.RawQuery = path[i+1:]
+		path = path[:i]
+	}
+
+	u.Path += path
+	if 0 != len(query) {
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String()
+}
+
+// session 获取当前会话
+func (c *Client) session() (jar *cookiejar.Jar, token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.jar, c.token
+}
+
+// setSession 设置当前会话
+func (c *Client) setSession(jar *cookiejar.Jar, token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.jar = jar
+	c.token = token
+}
+
+// cachedDomain 从缓存中查找域名信息
+func (c *Client) cachedDomain(domain string) (info *DomainInfo, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, ok = c.domainInfoMap[domain]
+	return
+}
+
+// refreshDomainInfo 修改记录后刷新缓存的域名信息
+// 刷新失败时删除缓存，下次查找时重新获取，避免使用修改前的记录
+func (c *Client) refreshDomainInfo(ctx context.Context, domain string) {
+	if _, err := c.GetDomainInfoContext(ctx, domain); nil != err {
+		c.mu.Lock()
+		delete(c.domainInfoMap, domain)
+		c.mu.Unlock()
+	}
+}
+
+// lookupDomain 查找域名信息，缓存中没有时刷新域名列表
+func (c *Client) lookupDomain(ctx context.Context, domain string) (info *DomainInfo, err error) {
+	if v, ok := c.cachedDomain(domain); ok {
+		return v, nil
+	}
+
+	// 逐页刷新域名列表，找到后不再获取后面的页
+	err = c.EachDomainContext(ctx, func(d *Domain) error {
+		if d.Domain == domain {
+			return errFound
+		}
+
+		return nil
+	})
+	if nil != err && errFound != err {
+		return
+	}
+
+	if v, ok := c.cachedDomain(domain); ok {
+		return v, nil
+	}
+
+	err = ErrDomainNotFound
+	return
+}
+
+// newHTTPClient 创建发送请求使用的 http.Client
+func (c *Client) newHTTPClient(jar http.CookieJar) *http.Client {
+	if nil != c.httpClient {
+		hc := *c.httpClient
+		hc.Jar = jar
+		if nil != c.transport {
+			hc.Transport = c.transport
+		}
+
+		return &hc
+	}
+
+	tr := c.transport
+	if nil == tr {
+		tr = defaultTransport
+	}
+
+	return &http.Client{
+		Jar:       jar,
+		Transport: tr,
+		Timeout:   timeout,
+	}
+}
+
+// sleepContext 等待指定时间，ctx 被取消时立即返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// do 发送请求，需要登录的页面返回未登录状态时，自动重新登录并重放一次请求
+// 返回 响应内容
+func (c *Client) do(ctx context.Context, r *request) (all []byte, err error) {
+	_, token := c.session()
+
+	all, err = c.send(ctx, r)
+	if nil != err || r.noJar || nil != r.jar || r.noLoginCheck || scrape.LoggedIn(all) {
+		return
+	}
+
+	if !c.autoRelogin {
+		err = fmt.Errorf("%s session expired: %w", r.name, ErrNotLoggedIn)
+		return
+	}
+
+	err = c.relogin(ctx, token)
+	if nil != err {
+		err = fmt.Errorf("%s relogin err: %w", r.name, err)
+		return
+	}
+
+	replay := *r
+	if _, ok := r.form["token"]; ok { // 表单中的令牌随会话一起更新
+		_, token = c.session()
+
+		replay.form = url.Values{}
+		for k, v := range r.form {
+			replay.form[k] = v
+		}
+		replay.form.Set("token", token)
+	}
+
+	all, err = c.send(ctx, &replay)
+	if nil == err && !scrape.LoggedIn(all) {
+		err = fmt.Errorf("%s session expired: %w", r.name, ErrNotLoggedIn)
+	}
+
+	return
+}
+
+// relogin 会话过期后重新登录
+// 参数 oldToken 发现会话过期时使用的令牌，若其它请求已经重新登录过则不再登录
+func (c *Client) relogin(ctx context.Context, oldToken string) (err error) {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	c.mu.Lock()
+	token, user := c.token, c.user
+	c.mu.Unlock()
+
+	if "" != token && token != oldToken {
+		return nil
+	}
+
+	if "" == user {
+		return ErrNotLoggedIn
+	}
+
+	return c.LoginContext(ctx)
+}
+
+// send 发送请求，失败时按重试设置重试，ctx 被取消时立即中止请求及重试
+// 返回 响应内容
+func (c *Client) send(ctx context.Context, r *request) (all []byte, err error) {
+	method := r.method
+	if "" == method {
+		method = "GET"
+	}
+
+	var jar http.CookieJar
+	if nil != r.jar {
+		jar = r.jar
+	} else if !r.noJar {
+		j, _ := c.session()
+		if nil == j {
+			err = ErrNotLoggedIn
+			return
+		}
+
+		jar = j
+	}
+
+	times := c.retryTimes
+	if r.noRetry {
+		times = 1
+	}
+
+	retries := 0
+	for {
+		retries++
+		if retries > 1 {
+			if ctxErr := sleepContext(ctx, c.retryInterval); nil != ctxErr {
+				err = fmt.Errorf("%s canceled: %w", r.name, ctxErr)
+				return
+			}
+		} else if ctxErr := ctx.Err(); nil != ctxErr {
+			err = fmt.Errorf("%s canceled: %w", r.name, ctxErr)
+			return
+		}
+
+		var body *bytes.Buffer
+		if nil != r.form {
+			body = bytes.NewBufferString(r.form.Encode())
+		} else {
+			body = bytes.NewBuffer(make([]byte, 0))
+		}
+
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, method, c.url(r.path, r.query), body)
+		if nil != err {
+			if retries < times {
+				continue
+			} else {
+				err = fmt.Errorf("%s NewRequest err: %w", r.name, err)
+				return
+			}
+		}
+
+		if "" != r.referer {
+			req.Header.Add("Referer", c.url(r.referer, nil))
+		}
+
+		if nil != r.form {
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+		}
+
+		var res *http.Response
+		res, err = c.newHTTPClient(jar).Do(req)
+		if nil != err {
+			if ctxErr := ctx.Err(); nil != ctxErr {
+				err = fmt.Errorf("%s canceled: %w", r.name, ctxErr)
+				return
+			} else if retries < times {
+				continue
+			} else {
+				err = fmt.Errorf("%s Do err: %w", r.name, err)
+				return
+			}
+		}
+
+		all, err = ioutil.ReadAll(res.Body)
+		res.Body.Close()
+
+		if nil != err {
+			if ctxErr := ctx.Err(); nil != ctxErr {
+				err = fmt.Errorf("%s canceled: %w", r.name, ctxErr)
+				return
+			}
+		}
+
+		if http.StatusOK != res.StatusCode {
+			if retries < times {
+				continue
+			} else {
+				err = fmt.Errorf("%s Do errCode: %w", r.name, &HTTPStatusError{
+					URL:        req.URL.String(),
+					StatusCode: res.StatusCode,
+				})
+				return
+			}
+		}
+
+		if nil != err {
+			if retries < times {
+				continue
+			} else {
+				err = fmt.Errorf("%s ReadAll err: %w", r.name, err)
+				return
+			}
+		}
+
+		return
+	}
+}
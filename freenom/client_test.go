@@ -0,0 +1,198 @@
+package freenom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
+)
+
+func TestNewClient(t *testing.T) {
+	c, err := NewClient(
+		WithCredentials(freenomUser, freenomPwd),
+		WithBaseURL("http://127.0.0.1:8080/freenom"),
+		WithRetry(2, time.Millisecond),
+	)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if c.user != freenomUser || c.pwd != freenomPwd {
+		t.Errorf("credentials not set: %s %s", c.user, c.pwd)
+	}
+
+	if 2 != c.retryTimes || time.Millisecond != c.retryInterval {
+		t.Errorf("retry not set: %d %s", c.retryTimes, c.retryInterval)
+	}
+
+	params := url.Values{}
+	params.Add("managedns", freenomDomain)
+	if u := c.url(loginPath, params); "http://127.0.0.1:8080/freenom/clientarea.php?managedns=freenom-api.tk" != u {
+		t.Errorf("url err: %s", u)
+	}
+
+	if u := c.url(domainsPath+"?a=renewals", nil); "http://127.0.0.1:8080/freenom/domains.php?a=renewals" != u {
+		t.Errorf("url err: %s", u)
+	}
+
+	if _, err = NewClient(WithBaseURL("my.freenom.com")); nil == err {
+		t.Error("invalid base url accepted")
+	}
+
+	if _, err = NewClient(WithRetry(0, 0)); nil == err {
+		t.Error("invalid retry times accepted")
+	}
+}
+
+func TestClientNotLoggedIn(t *testing.T) {
+	c, err := NewClient()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if _, err = c.ListDomains(); nil == err {
+		t.Error("ListDomains should fail before Login")
+	}
+
+	if _, err = c.GetDomainInfo(freenomDomain); nil == err {
+		t.Error("GetDomainInfo should fail before Login")
+	}
+}
+
+func TestClientContextCancel(t *testing.T) {
+	var hits int32 // 在服务器的协程中修改
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(WithBaseURL(srv.URL), WithRetry(5, time.Hour))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	start := time.Now()
+	_, err = c.CheckFreeDomainPurchasableContext(ctx, "freenom-api")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect DeadlineExceeded, got: %v", err)
+	}
+
+	if time.Since(start) > time.Second*5 {
+		t.Errorf("retry loop not interrupted: %s", time.Since(start))
+	}
+
+	if n := atomic.LoadInt32(&hits); 1 != n {
+		t.Errorf("expect 1 request, got %d", n)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"OK","free_domains":[{"status":"AVAILABLE","domain":"freenom-api","tld":".tk","type":"FREE"}]}`))
+	}))
+	defer srv.Close()
+
+	var paths []string
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	c, err := NewClient(WithBaseURL(srv.URL+"/stub/"), WithTransport(rt))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	domains, err := c.CheckFreeDomainPurchasable("freenom-api")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 1 != len(domains) || "freenom-api.tk" != domains[0] {
+		t.Errorf("unexpected domains: %+v", domains)
+	}
+
+	if 1 != len(paths) || "/stub/"+checkAvailablePath != paths[0] {
+		t.Errorf("request not sent through transport: %+v", paths)
+	}
+
+	hc := &http.Client{Timeout: time.Second}
+	c, err = NewClient(WithHTTPClient(hc), WithTransport(rt))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if got := c.newHTTPClient(nil); got == hc || nil == got.Transport || time.Second != got.Timeout {
+		t.Errorf("http.Client not copied: %+v", got)
+	}
+
+	c, _ = NewClient()
+	if c.newHTTPClient(nil).Transport != c.newHTTPClient(nil).Transport {
+		t.Error("default transport not shared")
+	}
+}
+
+func TestClientAutoRelogin(t *testing.T) {
+	srv := newTestServer(t, freenomtest.Record{Type: "A", Name: "www", TTL: 3600, Value: "127.0.0.1"})
+
+	c, err := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	_, oldToken := c.session()
+	srv.ExpireSessions()
+
+	domains, err := c.ListDomains()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 2 != len(domains) {
+		t.Errorf("expect 2 domains after relogin, got %d", len(domains))
+	}
+
+	if _, token := c.session(); token == oldToken {
+		t.Error("token not refreshed after relogin")
+	}
+
+	// 表单中的令牌需要随重新登录一起更新
+	srv.ExpireSessions()
+	if err = c.AddRecord(freenomDomain, []DomainRecord{{Type: "A", Name: "ipv4", TTL: 3600, Value: "127.0.0.2"}}); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if d, _ := srv.Domain(freenomUser, freenomDomain); 2 != len(d.Records) {
+		t.Errorf("record not added after relogin: %+v", d.Records)
+	}
+
+	c, _ = NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd), WithAutoRelogin(false))
+	if err = c.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	srv.ExpireSessions()
+	if _, err = c.ListDomains(); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("expect ErrNotLoggedIn, got: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package freenom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+// ParseNetscapeCookies 解析浏览器导出的 Netscape 格式 cookies.txt
+// 参数 host 只保留属于该主机的 cookie，为空时保留全部
+func ParseNetscapeCookies(r io.Reader, host string) (cookies []*http.Cookie, err error) {
+	now := time.Now()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		httpOnly := false
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		}
+
+		if "" == line || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// domain includeSubdomains path secure expiry name value
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			err = fmt.Errorf("ParseNetscapeCookies invalid line: %s", line)
+			return
+		}
+
+		if !cookieHostMatch(fields[0], host) {
+			continue
+		}
+
+		var expiry int64
+		expiry, err = strconv.ParseInt(fields[4], 10, 64)
+		if nil != err {
+			err = fmt.Errorf("ParseNetscapeCookies ParseInt expiry %s err: %w", fields[4], err)
+			return
+		}
+
+		ck := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     fields[2],
+			Secure:   strings.EqualFold("TRUE", fields[3]),
+			HttpOnly: httpOnly,
+		}
+
+		if 0 != expiry { // 0 表示会话 cookie
+			ck.Expires = time.Unix(expiry, 0)
+			if ck.Expires.Before(now) {
+				continue
+			}
+		}
+
+		cookies = append(cookies, ck)
+	}
+
+	if err = scanner.Err(); nil != err {
+		err = fmt.Errorf("ParseNetscapeCookies Scan err: %w", err)
+	}
+
+	return
+}
+
+// harCookie HAR 文件中的 cookie
+type harCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harHeader HAR 文件中的头部
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harFile HAR 文件中用到的部分
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL     string       `json:"url"`
+				Headers []*harHeader `json:"headers"`
+				Cookies []*harCookie `json:"cookies"`
+			} `json:"request"`
+			Response struct {
+				Cookies []*harCookie `json:"cookies"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// ParseHARCookies 从浏览器导出的 HAR 文件中提取 cookie
+// 按请求顺序合并请求携带的及响应设置的 cookie，同名 cookie 以最后出现的为准
+// 参数 host 只保留发往该主机的请求中的 cookie，为空时保留全部
+func ParseHARCookies(r io.Reader, host string) (cookies []*http.Cookie, err error) {
+	var har harFile
+	if err = json.NewDecoder(r).Decode(&har); nil != err {
+		err = fmt.Errorf("ParseHARCookies Decode err: %w", err)
+		return
+	}
+
+	var names []string
+	values := make(map[string]string)
+	set := func(name, value string) {
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+
+		values[name] = value
+	}
+
+	for _, entry := range har.Log.Entries {
+		u, e := url.Parse(entry.Request.URL)
+		if nil != e || ("" != host && !cookieHostMatch(u.Hostname(), host)) {
+			continue
+		}
+
+		if 0 != len(entry.Request.Cookies) {
+			for _, ck := range entry.Request.Cookies {
+				set(ck.Name, ck.Value)
+			}
+		} else { // 部分工具只记录了 Cookie 头部
+			req := &http.Request{Header: http.Header{}}
+			for _, h := range entry.Request.Headers {
+				if strings.EqualFold("Cookie", h.Name) {
+					req.Header.Add("Cookie", h.Value)
+				}
+			}
+
+			for _, ck := range req.Cookies() {
+				set(ck.Name, ck.Value)
+			}
+		}
+
+		for _, ck := range entry.Response.Cookies {
+			set(ck.Name, ck.Value)
+		}
+	}
+
+	for _, name := range names {
+		cookies = append(cookies, &http.Cookie{
+			Name:  name,
+			Value: values[name],
+		})
+	}
+
+	return
+}
+
+// cookieHostMatch 判断 cookie 所属的域是否匹配主机
+func cookieHostMatch(domain, host string) bool {
+	if "" == host {
+		return true
+	}
+
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	host = strings.ToLower(host)
+	return domain == host || strings.HasSuffix(host, "."+domain)
+}
+
+// LoginWithCookies 使用浏览器中已登录的 cookie 建立会话
+// 等同于使用 context.Background() 调用 LoginWithCookiesContext
+func (c *Client) LoginWithCookies(cookies []*http.Cookie) (err error) {
+	return c.LoginWithCookiesContext(context.Background(), cookies)
+}
+
+// LoginWithCookiesContext 使用浏览器中已登录的 cookie 建立会话
+// 适用于 dologin.php 前出现验证码或 Google 登录而无法使用 Login 的情况
+// 会从 clientarea.php 中获取新的令牌，之后与 Login 成功后的效果相同
+// ctx 被取消时中止请求及重试
+func (c *Client) LoginWithCookiesContext(ctx context.Context, cookies []*http.Cookie) (err error) {
+	var jar *cookiejar.Jar
+	jar, err = cookiejar.New(nil)
+	if nil != err {
+		err = fmt.Errorf("LoginWithCookies New Jar err: %w", err)
+		return
+	}
+
+	// 浏览器导出的 cookie 可能带有其它路径或 Secure 标记，统一设置到站点根路径
+	imported := make([]*http.Cookie, 0, len(cookies))
+	for _, ck := range cookies {
+		imported = append(imported, &http.Cookie{
+			Name:  ck.Name,
+			Value: ck.Value,
+			Path:  "/",
+		})
+	}
+	jar.SetCookies(c.baseURL, imported)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    "LoginWithCookies",
+		path:    loginPath,
+		referer: loginPath,
+		jar:     jar,
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.ClientAreaPage
+	page, err = scrape.ParseClientArea(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("LoginWithCookies %w", err)
+		return
+	}
+
+	if !page.LoggedIn {
+		err = fmt.Errorf("LoginWithCookies cookies not logged in: %w", ErrNotLoggedIn)
+		return
+	}
+
+	if "" == page.Token {
+		err = fmt.Errorf("LoginWithCookies token not found: %w", ErrUnexpectedResponse)
+		return
+	}
+
+	c.setSession(jar, page.Token)
+	return
+}
+
+// ImportCookiesFile 从浏览器导出的 cookies.txt 或 HAR 文件建立会话
+// 等同于使用 context.Background() 调用 ImportCookiesFileContext
+func (c *Client) ImportCookiesFile(path string) (err error) {
+	return c.ImportCookiesFileContext(context.Background(), path)
+}
+
+// ImportCookiesFileContext 从浏览器导出的 cookies.txt 或 HAR 文件建立会话
+// 文件内容以 { 开头时按 HAR 格式解析，否则按 Netscape cookies.txt 格式解析
+// ctx 被取消时中止请求及重试
+func (c *Client) ImportCookiesFileContext(ctx context.Context, path string) (err error) {
+	var all []byte
+	all, err = ioutil.ReadFile(path)
+	if nil != err {
+		err = fmt.Errorf("ImportCookiesFile ReadFile err: %w", err)
+		return
+	}
+
+	var cookies []*http.Cookie
+	if bytes.HasPrefix(bytes.TrimSpace(all), []byte("{")) {
+		cookies, err = ParseHARCookies(bytes.NewReader(all), c.baseURL.Hostname())
+	} else {
+		cookies, err = ParseNetscapeCookies(bytes.NewReader(all), c.baseURL.Hostname())
+	}
+
+	if nil != err {
+		return
+	}
+
+	if 0 == len(cookies) {
+		err = fmt.Errorf("ImportCookiesFile no cookies for %s: %w", c.baseURL.Hostname(), ErrNotLoggedIn)
+		return
+	}
+
+	return c.LoginWithCookiesContext(ctx, cookies)
+}
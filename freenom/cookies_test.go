@@ -0,0 +1,113 @@
+package freenom
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
+)
+
+func TestParseNetscapeCookies(t *testing.T) {
+	const cookiesTxt = "# Netscape HTTP Cookie File\n" +
+		"\n" +
+		".freenom.com\tTRUE\t/\tFALSE\t0\t__utmc\t76711234\n" +
+		"#HttpOnly_my.freenom.com\tFALSE\t/\tTRUE\t0\tWHMCSZH5eHTGhfvzP\tono3trb73dh7mfkvcvs0jl35k7\n" +
+		"my.freenom.com\tFALSE\t/\tTRUE\t1\tWHMCSUser\texpired\n" +
+		".google.com\tTRUE\t/\tFALSE\t0\tNID\tother\n"
+
+	cookies, err := ParseNetscapeCookies(strings.NewReader(cookiesTxt), "my.freenom.com")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 2 != len(cookies) || "__utmc" != cookies[0].Name ||
+		freenomtest.SessionCookie != cookies[1].Name || !cookies[1].HttpOnly || !cookies[1].Secure {
+		t.Errorf("unexpected cookies: %+v", cookies)
+	}
+
+	if _, err = ParseNetscapeCookies(strings.NewReader("my.freenom.com\tFALSE\n"), ""); nil == err {
+		t.Error("invalid line accepted")
+	}
+}
+
+func TestParseHARCookies(t *testing.T) {
+	const har = `{"log":{"entries":[
+		{"request":{"url":"https://my.freenom.com/domains.php","headers":[{"name":"Cookie","value":"WHMCSZH5eHTGhfvzP=old; WHMCSUser=1011206217%3Aceaed2f6"}],"cookies":[]},"response":{"cookies":[]}},
+		{"request":{"url":"https://www.google.com/","cookies":[{"name":"NID","value":"other"}]},"response":{"cookies":[]}},
+		{"request":{"url":"https://my.freenom.com/cart.php","cookies":[{"name":"WHMCSZH5eHTGhfvzP","value":"old"}]},"response":{"cookies":[{"name":"WHMCSZH5eHTGhfvzP","value":"new"}]}}
+	]}}`
+
+	cookies, err := ParseHARCookies(strings.NewReader(har), "my.freenom.com")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	got := make(map[string]string)
+	for _, ck := range cookies {
+		got[ck.Name] = ck.Value
+	}
+
+	if 2 != len(got) || "new" != got[freenomtest.SessionCookie] || "1011206217%3Aceaed2f6" != got[freenomtest.UserCookie] {
+		t.Errorf("unexpected cookies: %+v", got)
+	}
+}
+
+func TestImportCookiesFile(t *testing.T) {
+	srv := newTestServer(t)
+
+	// 模拟在浏览器中登录后导出 cookie
+	browser, _ := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd))
+	if err := browser.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	u, _ := url.Parse(srv.URL)
+	jar, _ := browser.session()
+
+	var txt strings.Builder
+	txt.WriteString("# Netscape HTTP Cookie File\n")
+	for _, ck := range jar.Cookies(u) {
+		fmt.Fprintf(&txt, "#HttpOnly_%s\tFALSE\t/\tFALSE\t0\t%s\t%s\n", u.Hostname(), ck.Name, ck.Value)
+	}
+
+	dir, err := ioutil.TempDir("", "freenom")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cookies.txt")
+	if err = ioutil.WriteFile(path, []byte(txt.String()), 0600); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	c, _ := NewClient(WithBaseURL(srv.URL))
+	if err = c.ImportCookiesFile(path); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	_, token := browser.session()
+	if _, imported := c.session(); token != imported {
+		t.Errorf("token not scraped: %s != %s", imported, token)
+	}
+
+	if err = c.AddRecord(freenomDomain, []DomainRecord{{Type: "A", Name: "www", TTL: 3600, Value: "127.0.0.1"}}); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 1 != srv.Logins() {
+		t.Errorf("expect 1 login, got %d", srv.Logins())
+	}
+
+	// 会话过期的 cookie 无法建立会话
+	srv.ExpireSessions()
+	if err = c.ImportCookiesFile(path); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("expect ErrNotLoggedIn, got: %v", err)
+	}
+}
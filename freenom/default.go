@@ -0,0 +1,153 @@
+package freenom
+
+import "context"
+
+// 包级函数使用的默认客户端
+var defaultClient, _ = NewClient()
+
+// DefaultClient 获取包级函数使用的默认客户端
+func DefaultClient() *Client {
+	return defaultClient
+}
+
+// Login 登录
+func Login(user, pwd string) (err error) {
+	defaultClient.SetCredentials(user, pwd)
+	return defaultClient.Login()
+}
+
+// LoginContext 登录，ctx 被取消时中止请求及重试
+func LoginContext(ctx context.Context, user, pwd string) (err error) {
+	defaultClient.SetCredentials(user, pwd)
+	return defaultClient.LoginContext(ctx)
+}
+
+// ListDomains 列出用户拥有的所有域名
+// 返回 域名与到期时间
+func ListDomains() (domains map[string]string, err error) {
+	return defaultClient.ListDomains()
+}
+
+// ListDomainsContext 列出用户拥有的所有域名
+// ctx 被取消时中止请求及重试
+func ListDomainsContext(ctx context.Context) (domains map[string]string, err error) {
+	return defaultClient.ListDomainsContext(ctx)
+}
+
+// GetDomainInfo 获取指定域名的信息
+// 返回 域名信息
+func GetDomainInfo(domain string) (info *DomainInfo, err error) {
+	return defaultClient.GetDomainInfo(domain)
+}
+
+// GetDomainInfoContext 获取指定域名的信息
+// ctx 被取消时中止请求及重试
+func GetDomainInfoContext(ctx context.Context, domain string) (info *DomainInfo, err error) {
+	return defaultClient.GetDomainInfoContext(ctx, domain)
+}
+
+// AddRecord 增加域名记录
+func AddRecord(domain string, records []DomainRecord) (err error) {
+	return defaultClient.AddRecord(domain, records)
+}
+
+// AddRecordContext 增加域名记录
+// ctx 被取消时中止请求及重试
+func AddRecordContext(ctx context.Context, domain string, records []DomainRecord) (err error) {
+	return defaultClient.AddRecordContext(ctx, domain, records)
+}
+
+// ModifyRecord 修改一条域名记录
+func ModifyRecord(domain string, oldRecord, newRecord *DomainRecord) (err error) {
+	return defaultClient.ModifyRecord(domain, oldRecord, newRecord)
+}
+
+// ModifyRecordContext 修改一条域名记录
+// ctx 被取消时中止请求及重试
+func ModifyRecordContext(ctx context.Context, domain string, oldRecord, newRecord *DomainRecord) (err error) {
+	return defaultClient.ModifyRecordContext(ctx, domain, oldRecord, newRecord)
+}
+
+// DeleteRecordByIndex 根据缓存的信息删除一条域名记录
+func DeleteRecordByIndex(domain string, recordIndex int) (err error) {
+	return defaultClient.DeleteRecordByIndex(domain, recordIndex)
+}
+
+// DeleteRecordByIndexContext 根据缓存的信息删除一条域名记录
+// ctx 被取消时中止请求及重试
+func DeleteRecordByIndexContext(ctx context.Context, domain string, recordIndex int) (err error) {
+	return defaultClient.DeleteRecordByIndexContext(ctx, domain, recordIndex)
+}
+
+// DeleteRecord 根据参数删除一条域名记录
+func DeleteRecord(domain string, record *DomainRecord) (err error) {
+	return defaultClient.DeleteRecord(domain, record)
+}
+
+// DeleteRecordContext 根据参数删除一条域名记录
+// ctx 被取消时中止请求及重试
+func DeleteRecordContext(ctx context.Context, domain string, record *DomainRecord) (err error) {
+	return defaultClient.DeleteRecordContext(ctx, domain, record)
+}
+
+// RenewFreeDomain 免费域名续期
+// 参数 domain 若为空字符串，则续期所有域名，否则只续期指定域名
+// 参数 months 要续期的月份数，最少 1 个月，最多 12 个月
+// 返回 可续期域名列表中所有域名的续期结果
+func RenewFreeDomain(domain string, months int) (renewals []*Renewal, err error) {
+	return defaultClient.RenewFreeDomain(domain, months)
+}
+
+// RenewFreeDomainContext 免费域名续期
+// ctx 被取消时中止请求及重试
+func RenewFreeDomainContext(ctx context.Context, domain string, months int) (renewals []*Renewal, err error) {
+	return defaultClient.RenewFreeDomainContext(ctx, domain, months)
+}
+
+// PlanRenewFreeDomain 列出 RenewFreeDomain 将会续期及跳过的域名，不会提交续期
+// 返回 可续期域名列表中的所有域名，将会续期的域名状态为 RenewalPlanned
+func PlanRenewFreeDomain(domain string, months int) (renewals []*Renewal, err error) {
+	return defaultClient.PlanRenewFreeDomain(domain, months)
+}
+
+// PlanRenewFreeDomainContext 列出 RenewFreeDomain 将会续期及跳过的域名，不会提交续期
+// ctx 被取消时中止请求及重试
+func PlanRenewFreeDomainContext(ctx context.Context, domain string, months int) (renewals []*Renewal, err error) {
+	return defaultClient.PlanRenewFreeDomainContext(ctx, domain, months)
+}
+
+// RenewPlanned 提交 PlanRenewFreeDomain 列出的续期，只提交状态为 RenewalPlanned 的域名，结果写入各 Renewal
+func RenewPlanned(renewals []*Renewal) (err error) {
+	return defaultClient.RenewPlanned(renewals)
+}
+
+// RenewPlannedContext 提交 PlanRenewFreeDomain 列出的续期
+// ctx 被取消时中止请求及重试
+func RenewPlannedContext(ctx context.Context, renewals []*Renewal) (err error) {
+	return defaultClient.RenewPlannedContext(ctx, renewals)
+}
+
+// CheckFreeDomainPurchasable 检查免费域名是否可购买
+// 返回 可注册免费域名列表
+func CheckFreeDomainPurchasable(domainPrefix string) (availableDomains []string, err error) {
+	return defaultClient.CheckFreeDomainPurchasable(domainPrefix)
+}
+
+// CheckFreeDomainPurchasableContext 检查免费域名是否可购买
+// ctx 被取消时中止请求及重试
+func CheckFreeDomainPurchasableContext(ctx context.Context, domainPrefix string) (availableDomains []string, err error) {
+	return defaultClient.CheckFreeDomainPurchasableContext(ctx, domainPrefix)
+}
+
+// PurchaseFreeDomain 购买免费域名（网站做了 GOOGLE 的反机器人校验，需要先通过 DefaultClient().SetCaptchaSolver 设置 CaptchaSolver）
+// 参数 months 注册月数，1 至 12
+// 返回 订单号
+func PurchaseFreeDomain(domain string, months int, opts *PurchaseOptions) (orderNumber string, err error) {
+	return defaultClient.PurchaseFreeDomain(domain, months, opts)
+}
+
+// PurchaseFreeDomainContext 购买免费域名
+// ctx 被取消时中止请求及重试
+func PurchaseFreeDomainContext(ctx context.Context, domain string, months int, opts *PurchaseOptions) (orderNumber string, err error) {
+	return defaultClient.PurchaseFreeDomainContext(ctx, domain, months, opts)
+}
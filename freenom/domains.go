@@ -0,0 +1,205 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+// 域名列表中的域名状态
+const (
+	StatusActive    string = "Active"    // 正常使用中
+	StatusCancelled string = "Cancelled" // 已取消
+	StatusExpired   string = "Expired"   // 已过期
+	StatusPending   string = "Pending"   // 等待注册完成
+)
+
+// 域名列表中的域名类型
+const (
+	TypeFree string = "Free" // 免费域名
+	TypePaid string = "Paid" // 付费域名
+)
+
+// 域名列表中日期的格式
+const dateLayout string = "2006-01-02"
+
+// 域名列表最多跟随的翻页次数，避免翻页链接异常时无限请求
+const maxDomainPages int = 1000
+
+// Domain 域名列表中的一个域名
+type Domain struct {
+	Domain   string
+	DomainID string
+	RegDate  time.Time // 注册日期，网站上的日期不带时区，按 UTC 零点解析
+	ExpDate  time.Time // 到期日期，网站上的日期不带时区，按 UTC 零点解析
+	Status   string    // 见 StatusActive 等常量
+	Type     string    // 见 TypeFree 等常量
+}
+
+// DaysUntilExpiry 距离到期的天数，已过期时为负数
+func (d *Domain) DaysUntilExpiry() int {
+	return d.daysUntilExpiry(time.Now())
+}
+
+// daysUntilExpiry 从 now 所在的日期开始计算距离到期的天数
+// 网站上的日期不带时区，因此按日历日期比较
+func (d *Domain) daysUntilExpiry(now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(d.ExpDate.Sub(today).Hours() / 24)
+}
+
+// IsFree 是否为免费域名
+func (d *Domain) IsFree() bool {
+	return TypeFree == d.Type
+}
+
+// Domains 列出用户拥有的所有域名及其状态
+// 等同于使用 context.Background() 调用 DomainsContext
+func (c *Client) Domains() (domains []*Domain, err error) {
+	return c.DomainsContext(context.Background())
+}
+
+// DomainsContext 列出用户拥有的所有域名及其状态
+// 返回 按网站上的顺序排列的域名列表（包含所有分页），同时刷新域名信息缓存
+// ctx 被取消时中止请求及重试
+func (c *Client) DomainsContext(ctx context.Context) (domains []*Domain, err error) {
+	domains = make([]*Domain, 0)
+	err = c.EachDomainContext(ctx, func(d *Domain) error {
+		domains = append(domains, d)
+		return nil
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	return
+}
+
+// EachDomain 逐页遍历用户拥有的所有域名
+// 等同于使用 context.Background() 调用 EachDomainContext
+func (c *Client) EachDomain(fn func(d *Domain) error) (err error) {
+	return c.EachDomainContext(context.Background(), fn)
+}
+
+// EachDomainContext 逐页遍历用户拥有的所有域名，适用于域名很多的账号
+// 每获取一页即刷新该页域名的缓存，并按顺序对每个域名调用 fn
+// fn 返回非 nil 的错误时停止遍历，并返回该错误
+// ctx 被取消时中止请求及重试
+func (c *Client) EachDomainContext(ctx context.Context, fn func(d *Domain) error) (err error) {
+	query := url.Values{}
+	query.Set("action", "domains")
+
+	referer := loginPath
+	seen := make(map[string]bool)
+
+	for n := 0; n < maxDomainPages; n++ {
+		seen[query.Encode()] = true
+
+		var all []byte
+		all, err = c.do(ctx, &request{
+			name:    "ListDomains",
+			path:    loginPath,
+			query:   query,
+			referer: referer,
+		})
+		if nil != err {
+			return
+		}
+
+		var page *scrape.DomainsPage
+		page, err = scrape.ParseDomains(bytes.NewReader(all))
+		if nil != err {
+			return fmt.Errorf("ListDomains %w", err)
+		}
+
+		domains := make([]*Domain, 0, len(page.Domains))
+		for _, row := range page.Domains {
+			var d *Domain
+			d, err = newDomain(row)
+			if nil != err {
+				return
+			}
+
+			domains = append(domains, d)
+		}
+
+		c.cacheDomains(page.Domains)
+
+		for _, d := range domains {
+			if err = fn(d); nil != err {
+				return
+			}
+		}
+
+		if "" == page.NextPage {
+			return
+		}
+
+		var next *url.URL
+		next, err = url.Parse(page.NextPage)
+		if nil != err {
+			return fmt.Errorf("ListDomains Parse next page %s err: %w", page.NextPage, ErrUnexpectedResponse)
+		}
+
+		referer = loginPath + "?" + query.Encode()
+		query = next.Query()
+		if seen[query.Encode()] { // 翻页链接指向已获取过的页面
+			return
+		}
+	}
+
+	// 翻页次数达到上限时不能确定已获取所有域名，不返回不完整的列表
+	return fmt.Errorf("ListDomains too many pages: %w", ErrUnexpectedResponse)
+}
+
+// cacheDomains 使用域名列表刷新域名信息缓存
+func (c *Client) cacheDomains(rows []*scrape.DomainRow) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, row := range rows {
+		if v, ok := c.domainInfoMap[row.Domain]; ok { // 缓存已存在，保留 DNS 记录
+			v.Domain = row.Domain
+			v.DomainID = row.ID
+			v.RegDate = row.RegDate
+			v.ExpDate = row.ExpDate
+		} else {
+			c.domainInfoMap[row.Domain] = &DomainInfo{
+				Domain:   row.Domain,
+				DomainID: row.ID,
+				RegDate:  row.RegDate,
+				ExpDate:  row.ExpDate,
+			}
+		}
+	}
+}
+
+// newDomain 将域名列表中的一行转换为 Domain
+func newDomain(row *scrape.DomainRow) (d *Domain, err error) {
+	d = &Domain{
+		Domain:   row.Domain,
+		DomainID: row.ID,
+		Status:   row.Status,
+		Type:     row.Type,
+	}
+
+	if "" != row.RegDate {
+		d.RegDate, err = time.Parse(dateLayout, row.RegDate)
+		if nil != err {
+			return nil, fmt.Errorf("ListDomains Parse RegDate %s err: %w", row.RegDate, ErrUnexpectedResponse)
+		}
+	}
+
+	if "" != row.ExpDate {
+		d.ExpDate, err = time.Parse(dateLayout, row.ExpDate)
+		if nil != err {
+			return nil, fmt.Errorf("ListDomains Parse ExpDate %s err: %w", row.ExpDate, ErrUnexpectedResponse)
+		}
+	}
+
+	return
+}
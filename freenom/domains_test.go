@@ -0,0 +1,146 @@
+package freenom
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
+)
+
+func TestDomains(t *testing.T) {
+	srv := newTestServer(t)
+
+	now := time.Now()
+	srv.AddDomain(freenomUser, freenomtest.Domain{
+		Name:    "freenom-api.ga",
+		RegDate: now.AddDate(-2, 0, 0),
+		ExpDate: now.AddDate(0, 0, -3),
+		Status:  StatusCancelled,
+		Type:    TypePaid,
+	})
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	domains, err := DefaultClient().Domains()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	want := []struct {
+		domain string
+		status string
+		typ    string
+		days   int
+	}{
+		{freenomDomain, StatusActive, TypeFree, 10},
+		{"freenom-api.ml", StatusActive, TypeFree, int(today.AddDate(0, 10, 0).Sub(today).Hours() / 24)},
+		{"freenom-api.ga", StatusCancelled, TypePaid, -3},
+	}
+
+	if len(want) != len(domains) {
+		t.Fatalf("expect %d domains, got %d", len(want), len(domains))
+	}
+
+	for i, w := range want {
+		d := domains[i]
+		if w.domain != d.Domain || w.status != d.Status || w.typ != d.Type || "" == d.DomainID {
+			t.Errorf("unexpected domain %d: %+v", i, d)
+		}
+
+		if days := d.DaysUntilExpiry(); w.days != days {
+			t.Errorf("%s expect %d days until expiry, got %d", d.Domain, w.days, days)
+		}
+
+		if exp := today.AddDate(0, 0, w.days).Format(dateLayout); exp != d.ExpDate.Format(dateLayout) {
+			t.Errorf("%s expect expiry date %s, got %s", d.Domain, exp, d.ExpDate.Format(dateLayout))
+		}
+	}
+
+	if !domains[0].IsFree() || domains[2].IsFree() {
+		t.Error("IsFree mismatch")
+	}
+
+	// 同时刷新域名信息缓存
+	if info, ok := DefaultClient().cachedDomain("freenom-api.ga"); !ok || domains[2].DomainID != info.DomainID {
+		t.Error("domain cache not refreshed")
+	}
+}
+
+func TestDomainsPagination(t *testing.T) {
+	srv := newTestServer(t)
+	srv.DomainsPerPage = 2
+
+	now := time.Now()
+	names := []string{freenomDomain, "freenom-api.ml"}
+	for _, name := range []string{"freenom-api.ga", "freenom-api.cf", "freenom-api.gq"} {
+		srv.AddDomain(freenomUser, freenomtest.Domain{
+			Name:    name,
+			RegDate: now,
+			ExpDate: now.AddDate(1, 0, 0),
+		})
+		names = append(names, name)
+	}
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	domains, err := DefaultClient().Domains()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if len(names) != len(domains) {
+		t.Fatalf("expect %d domains, got %d", len(names), len(domains))
+	}
+
+	for i, name := range names {
+		if name != domains[i].Domain {
+			t.Errorf("expect domain %d to be %s, got %s", i, name, domains[i].Domain)
+		}
+	}
+
+	// 遍历时 fn 返回错误则停止
+	errStop := errors.New("stop")
+	count := 0
+	err = DefaultClient().EachDomain(func(d *Domain) error {
+		count++
+		if "freenom-api.ga" == d.Domain {
+			return errStop
+		}
+
+		return nil
+	})
+	if errStop != err || 3 != count {
+		t.Errorf("expect to stop at the 3rd domain, got %d: %v", count, err)
+	}
+
+	// 最后一页上的域名也能通过新的客户端找到
+	c, _ := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd))
+	if err = c.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if _, err = c.GetDomainInfo("freenom-api.gq"); nil != err {
+		t.Error(err.Error())
+	}
+
+	// 翻页次数超过上限时返回错误，而不是不完整的列表
+	srv.DomainsPerPage = 1
+	for i := len(names); i <= maxDomainPages; i++ {
+		srv.AddDomain(freenomUser, freenomtest.Domain{
+			Name:    fmt.Sprintf("freenom-api-%d.tk", i),
+			RegDate: now,
+			ExpDate: now.AddDate(1, 0, 0),
+		})
+	}
+
+	if domains, err = c.Domains(); !errors.Is(err, ErrUnexpectedResponse) || nil != domains {
+		t.Errorf("expect ErrUnexpectedResponse, got %d domains: %v", len(domains), err)
+	}
+}
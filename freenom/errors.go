@@ -0,0 +1,98 @@
+package freenom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 可以通过 errors.Is 判断的错误
+// 为了兼容按字符串判断错误的旧代码，错误信息与之前保持一致
+var (
+	ErrNotLoggedIn        = errors.New("NOT LOGGED IN")                     // 尚未登录或会话已失效
+	ErrInvalidCredentials = errors.New("Login failed")                      // 账号或密码错误
+	ErrDomainNotFound     = errors.New("Domain not exists")                 // 账号下不存在该域名
+	ErrRecordNotFound     = errors.New("Record not exists")                 // 域名下不存在该记录
+	ErrEmptyRecords       = errors.New("Empty records")                     // 没有要添加的记录
+	ErrInvalidPeriod      = errors.New("months should be between 1 and 12") // 续期月份数不合法
+	ErrUnexpectedResponse = errors.New("Unexpected response")               // 页面内容无法识别
+	ErrInvalidNameservers = errors.New("Invalid nameservers")               // 域名服务器数量或主机名不合法
+	ErrInvalidIP          = errors.New("Invalid IP address")                // 不是合法的 IPv4 或 IPv6 地址
+	ErrDomainUnavailable  = errors.New("Domain not available")              // 域名已被注册或不是免费域名
+	ErrNoCaptchaSolver    = errors.New("Captcha solver not set")            // 需要求解验证码，但客户端没有设置 CaptchaSolver
+	ErrInvalidPrefix      = errors.New("Invalid domain prefix")             // 域名前缀不合法
+	ErrInvalidForwarding  = errors.New("Invalid URL forwarding")            // URL 转发设置为空，或转发地址、转发方式不合法
+	ErrInvalidSession     = errors.New("Invalid session")                   // 会话为空，或保存会话的站点与客户端不同
+)
+
+// errFound 遍历时找到目标后用于提前结束遍历，不会返回给调用方
+var errFound = errors.New("found")
+
+// DNSError Freenom 拒绝 DNS 记录操作时返回的错误
+type DNSError struct {
+	Op      string        // 操作名称，例如 AddRecord
+	Domain  string        // 域名
+	Message string        // 服务器返回的错误信息
+	Record  *DomainRecord // 出错的记录，无法确定时为 nil
+}
+
+func (e *DNSError) Error() string {
+	if nil == e.Record {
+		return fmt.Sprintf("%s %s: %s", e.Op, e.Domain, e.Message)
+	}
+
+	return fmt.Sprintf("%s %s record %s %q: %s", e.Op, e.Domain, e.Record.Type, e.Record.Name, e.Message)
+}
+
+// ManageError Freenom 拒绝域名管理操作（例如 URL 转发设置）或订单时返回的错误
+type ManageError struct {
+	Op      string // 操作名称，例如 SetURLForwarding、PurchaseFreeDomain
+	Domain  string // 域名，操作整个购物车时为空
+	Message string // 服务器返回的错误信息
+}
+
+func (e *ManageError) Error() string {
+	if "" == e.Domain {
+		return fmt.Sprintf("%s: %s", e.Op, e.Message)
+	}
+
+	return fmt.Sprintf("%s %s: %s", e.Op, e.Domain, e.Message)
+}
+
+// GlueError 注册局拒绝子域名服务器（glue 记录）操作时返回的错误
+type GlueError struct {
+	Op      string // 操作名称，例如 CreateGlueRecord
+	Domain  string // 域名
+	Host    string // 子域名服务器的完整主机名
+	Message string // 服务器返回的错误信息
+}
+
+func (e *GlueError) Error() string {
+	return fmt.Sprintf("%s %s host %s: %s", e.Op, e.Domain, e.Host, e.Message)
+}
+
+// HTTPStatusError 服务器返回了非 200 的状态码
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %d from %s", e.StatusCode, e.URL)
+}
+
+// failedRecord 根据服务器返回的错误信息推断出错的记录
+// 只提交了一条记录时即为该记录，否则查找错误信息中提到的记录值
+func failedRecord(records []DomainRecord, msg string) *DomainRecord {
+	if 1 == len(records) {
+		return &records[0]
+	}
+
+	for i := range records {
+		if "" != records[i].Value && strings.Contains(msg, records[i].Value) {
+			return &records[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package freenom
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
+)
+
+func TestErrors(t *testing.T) {
+	newTestServer(t, freenomtest.Record{Type: "A", Name: "www", TTL: 3600, Value: "127.0.0.1"})
+
+	if _, err := ListDomains(); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("expect ErrNotLoggedIn, got: %v", err)
+	}
+
+	if err := Login(freenomUser, "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expect ErrInvalidCredentials, got: %v", err)
+	}
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := GetDomainInfo("not-mine.tk"); !errors.Is(err, ErrDomainNotFound) {
+		t.Errorf("expect ErrDomainNotFound, got: %v", err)
+	}
+
+	if _, err := RenewFreeDomain("", 0); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("expect ErrInvalidPeriod, got: %v", err)
+	}
+
+	if err := AddRecord(freenomDomain, nil); !errors.Is(err, ErrEmptyRecords) {
+		t.Errorf("expect ErrEmptyRecords, got: %v", err)
+	}
+
+	bad := DomainRecord{Type: "A", Name: "bad", TTL: 3600, Value: "::1"}
+	err := AddRecord(freenomDomain, []DomainRecord{bad})
+
+	var dnsErr *DNSError
+	if !errors.As(err, &dnsErr) {
+		t.Fatalf("expect DNSError, got: %v", err)
+	}
+
+	if "AddRecord" != dnsErr.Op || freenomDomain != dnsErr.Domain || "" == dnsErr.Message ||
+		nil == dnsErr.Record || bad != *dnsErr.Record {
+		t.Errorf("unexpected DNSError: %+v", dnsErr)
+	}
+
+	showRecords(freenomDomain, t)
+
+	missing := &DomainRecord{Type: "A", Name: "missing", TTL: 3600, Value: "127.0.0.2"}
+	if err = ModifyRecord(freenomDomain, missing, missing); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expect ErrRecordNotFound, got: %v", err)
+	}
+
+	if err = DeleteRecord(freenomDomain, missing); !errors.As(err, &dnsErr) || missing != dnsErr.Record {
+		t.Errorf("expect DNSError, got: %v", err)
+	}
+
+	if err = DeleteRecordByIndex(freenomDomain, 1); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expect ErrRecordNotFound, got: %v", err)
+	}
+}
+
+func TestHTTPStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(WithBaseURL(srv.URL), WithRetry(1, 0))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	_, err = c.CheckFreeDomainPurchasable("freenom-api")
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || http.StatusBadGateway != statusErr.StatusCode {
+		t.Errorf("expect HTTPStatusError, got: %v", err)
+	}
+}
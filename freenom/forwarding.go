@@ -0,0 +1,182 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+// URL 转发方式
+const (
+	ForwardTypeRedirect int = 1 // 301 跳转到目标地址
+	ForwardTypeFrame    int = 2 // 使用框架显示目标地址，浏览器地址栏保持为本域名（cloaking）
+)
+
+// URLForwarding 域名的 URL 转发设置
+type URLForwarding struct {
+	Enabled         bool   // 是否使用 URL 转发，为 false 时使用 Freenom DNS
+	URL             string // 转发的目标地址
+	Type            int    // 转发方式，见 ForwardTypeRedirect 等常量
+	Title           string // 框架页面的标题（仅 ForwardTypeFrame）
+	MetaDescription string // 框架页面的 description（仅 ForwardTypeFrame）
+	MetaKeywords    string // 框架页面的 keywords（仅 ForwardTypeFrame）
+}
+
+// forwardingPath URL 转发设置页的地址
+func forwardingPath(domainID string) (path string, query url.Values) {
+	query = url.Values{}
+	query.Add("action", "domaindetails")
+	query.Add("id", domainID)
+	query.Add("modop", "custom")
+	query.Add("a", "urlforwarding")
+	return loginPath, query
+}
+
+// GetURLForwarding 获取域名的 URL 转发设置
+// 等同于使用 context.Background() 调用 GetURLForwardingContext
+func (c *Client) GetURLForwarding(domain string) (fwd *URLForwarding, err error) {
+	return c.GetURLForwardingContext(context.Background(), domain)
+}
+
+// GetURLForwardingContext 获取域名的 URL 转发设置
+// ctx 被取消时中止请求及重试
+func (c *Client) GetURLForwardingContext(ctx context.Context, domain string) (fwd *URLForwarding, err error) {
+	if jar, _ := c.session(); nil == jar {
+		return nil, ErrNotLoggedIn
+	}
+
+	var info *DomainInfo
+	info, err = c.lookupDomain(ctx, domain)
+	if nil != err {
+		return
+	}
+
+	path, query := forwardingPath(info.DomainID)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    "GetURLForwarding",
+		path:    path,
+		query:   query,
+		referer: loginPath + "?action=domaindetails&id=" + info.DomainID,
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.URLForwardingPage
+	page, err = scrape.ParseURLForwarding(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("GetURLForwarding %w", err)
+		return
+	}
+
+	if "" == page.Mode {
+		err = fmt.Errorf("GetURLForwarding mode not found: %w", ErrUnexpectedResponse)
+		return
+	}
+
+	fwd = &URLForwarding{
+		Enabled:         "forward" == page.Mode,
+		URL:             page.URL,
+		Type:            page.ForwardType,
+		Title:           page.Title,
+		MetaDescription: page.MetaDescription,
+		MetaKeywords:    page.MetaKeywords,
+	}
+	return
+}
+
+// SetURLForwarding 修改域名的 URL 转发设置
+// 等同于使用 context.Background() 调用 SetURLForwardingContext
+func (c *Client) SetURLForwarding(domain string, fwd *URLForwarding) (err error) {
+	return c.SetURLForwardingContext(context.Background(), domain, fwd)
+}
+
+// SetURLForwardingContext 修改域名的 URL 转发设置
+// fwd.Enabled 为 true 时将域名切换为 URL 转发，为 false 时切换回 Freenom DNS（此时忽略其它字段）
+// ctx 被取消时中止请求及重试
+func (c *Client) SetURLForwardingContext(ctx context.Context, domain string, fwd *URLForwarding) (err error) {
+	jar, token := c.session()
+	if nil == jar {
+		return ErrNotLoggedIn
+	}
+
+	if nil == fwd {
+		return fmt.Errorf("SetURLForwarding forwarding not set: %w", ErrInvalidForwarding)
+	}
+
+	params := url.Values{}
+	params.Add("token", token)
+
+	if fwd.Enabled {
+		u, e := url.Parse(fwd.URL)
+		if nil != e || ("http" != strings.ToLower(u.Scheme) && "https" != strings.ToLower(u.Scheme)) || "" == u.Host {
+			return fmt.Errorf("SetURLForwarding %q: %w", fwd.URL, ErrInvalidForwarding)
+		}
+
+		typ := fwd.Type
+		if 0 == typ {
+			typ = ForwardTypeRedirect
+		}
+
+		if ForwardTypeRedirect != typ && ForwardTypeFrame != typ {
+			return fmt.Errorf("SetURLForwarding forward type %d: %w", fwd.Type, ErrInvalidForwarding)
+		}
+
+		params.Add("mode", "forward")
+		params.Add("url", fwd.URL)
+		params.Add("forward_type", strconv.Itoa(typ))
+		params.Add("title", fwd.Title)
+		params.Add("meta_description", fwd.MetaDescription)
+		params.Add("meta_keywords", fwd.MetaKeywords)
+	} else {
+		params.Add("mode", "dns")
+	}
+
+	var info *DomainInfo
+	info, err = c.lookupDomain(ctx, domain)
+	if nil != err {
+		return
+	}
+
+	path, query := forwardingPath(info.DomainID)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    "SetURLForwarding",
+		method:  "POST",
+		path:    path,
+		query:   query,
+		form:    params,
+		referer: path + "?" + query.Encode(),
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.URLForwardingPage
+	page, err = scrape.ParseURLForwarding(bytes.NewReader(all))
+	if nil != err {
+		return fmt.Errorf("SetURLForwarding %w", err)
+	}
+
+	if 0 != len(page.Errors) {
+		return &ManageError{
+			Op:      "SetURLForwarding",
+			Domain:  domain,
+			Message: strings.Join(page.Errors, "; "),
+		}
+	}
+
+	if 0 == len(page.Success) {
+		return fmt.Errorf("SetURLForwarding not success: %w", ErrUnexpectedResponse)
+	}
+
+	return
+}
@@ -0,0 +1,76 @@
+package freenom
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestURLForwarding(t *testing.T) {
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	c := DefaultClient()
+	fwd, err := c.GetURLForwarding(freenomDomain)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if fwd.Enabled {
+		t.Errorf("expect Freenom DNS mode, got %+v", fwd)
+	}
+
+	want := &URLForwarding{
+		Enabled:         true,
+		URL:             "https://example.com/?a=1&b=2",
+		Type:            ForwardTypeFrame,
+		Title:           `Zhang & Li "Shop"`,
+		MetaDescription: "Cheap <b>things</b>",
+		MetaKeywords:    "shop, cheap",
+	}
+	if err = c.SetURLForwarding(freenomDomain, want); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if d, _ := srv.Domain(freenomUser, freenomDomain); nil == d.Forwarding || want.URL != d.Forwarding.URL {
+		t.Errorf("forwarding not saved on server: %+v", d.Forwarding)
+	}
+
+	if fwd, err = c.GetURLForwarding(freenomDomain); nil != err {
+		t.Fatal(err.Error())
+	} else if *want != *fwd {
+		t.Errorf("expect %+v, got %+v", want, fwd)
+	}
+
+	// 切换回 Freenom DNS
+	if err = c.SetURLForwarding(freenomDomain, &URLForwarding{}); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if d, _ := srv.Domain(freenomUser, freenomDomain); nil != d.Forwarding {
+		t.Errorf("forwarding not disabled on server: %+v", d.Forwarding)
+	}
+
+	for _, bad := range []*URLForwarding{
+		nil,
+		{Enabled: true, URL: "ftp://example.com/"},
+		{Enabled: true, URL: "https://example.com/", Type: 3},
+	} {
+		if err = c.SetURLForwarding(freenomDomain, bad); !errors.Is(err, ErrInvalidForwarding) {
+			t.Errorf("expect ErrInvalidForwarding for %+v, got %v", bad, err)
+		}
+	}
+
+	// 服务器拒绝时返回 ManageError
+	var me *ManageError
+	err = c.SetURLForwarding(freenomDomain, &URLForwarding{Enabled: true, URL: "http://" + freenomDomain + "/"})
+	if !errors.As(err, &me) || freenomDomain != me.Domain {
+		t.Errorf("expect ManageError, got %v", err)
+	}
+
+	if _, err = c.GetURLForwarding("not-mine.tk"); !errors.Is(err, ErrDomainNotFound) {
+		t.Errorf("expect ErrDomainNotFound, got %v", err)
+	}
+}
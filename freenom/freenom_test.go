@@ -1,11 +1,16 @@
 package freenom
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
 	"log"
-	"regexp"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
 )
 
 const (
@@ -14,32 +19,58 @@ const (
 	freenomDomain string = "freenom-api.tk"
 )
 
-func TestRegExp(t *testing.T) {
-	re, err := regexp.Compile(`(?is:(records\[\d+\]\[type\])" value="([^"]*)".+?(records\[\d+\]\[name\])" value="([^"]*)".+?(records\[\d+\]\[ttl\])" value="(\d+)".+?(records\[\d+\]\[value\])" value="([^"]*)".+?(?:(records\[\d+\]\[priority\])" value="(\d+)".+?)?</td>)`)
-	// re, err := regexp.Compile(`(?is:(records\[\d+\]\[type\]).+?</td>)`)
+// newTestServer 启动模拟服务器，并让默认客户端指向它
+func newTestServer(t *testing.T, records ...freenomtest.Record) *freenomtest.Server {
+	srv := freenomtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	now := time.Now()
+	srv.AddAccount(freenomUser, freenomPwd)
+	srv.AddDomain(freenomUser, freenomtest.Domain{
+		Name:    freenomDomain,
+		RegDate: now.AddDate(-1, 0, 10),
+		ExpDate: now.AddDate(0, 0, 10),
+		Records: records,
+	})
+	srv.AddDomain(freenomUser, freenomtest.Domain{
+		Name:    "freenom-api.ml",
+		RegDate: now.AddDate(0, -2, 0),
+		ExpDate: now.AddDate(0, 10, 0),
+	})
+
+	var err error
+	defaultClient, err = NewClient(WithBaseURL(srv.URL), WithRetry(1, 0))
 	if nil != err {
-		t.Error(err.Error())
-		return
-	}
-
-	allBytes, _ := ioutil.ReadFile("D:\\abc.txt")
-	allM := re.FindAllSubmatch(allBytes, -1)
-	for _, m := range allM {
-		log.Printf("%+v\n", m)
+		t.Fatal(err.Error())
 	}
 
-	return
+	return srv
 }
 
 func TestLogin(t *testing.T) {
+	newTestServer(t)
+
 	if err := Login(freenomUser, freenomPwd); nil != err {
 		t.Error(err.Error())
 		return
 	}
+
+	if err := Login(freenomUser, "wrong password"); nil == err {
+		t.Error("Login with wrong password should fail")
+		return
+	}
+
+	if _, err := ListDomains(); nil == err {
+		t.Error("session should be cleared after a failed Login")
+	}
 }
 
 func TestListDomains(t *testing.T) {
-	TestLogin(t)
+	newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
 
 	if domains, err := ListDomains(); nil != err {
 		t.Error(err.Error())
@@ -48,31 +79,63 @@ func TestListDomains(t *testing.T) {
 		for domain, expDate := range domains {
 			log.Printf("Domain = %s Expiry Date = %s\n", domain, expDate)
 		}
+
+		if 2 != len(domains) {
+			t.Errorf("expect 2 domains, got %d", len(domains))
+		}
+
+		if expDate := time.Now().AddDate(0, 0, 10).Format("2006-01-02"); domains[freenomDomain] != expDate {
+			t.Errorf("expect expiry date %s, got %s", expDate, domains[freenomDomain])
+		}
 	}
 }
 
-func showRecords(domain string, t *testing.T) {
+func showRecords(domain string, t *testing.T) []*DomainRecord {
 	if info, err := GetDomainInfo(domain); nil != err {
 		t.Error(err.Error())
-		return
+		return nil
 	} else {
 		log.Printf("DomainID = %s DomainName = %s Registration Date = %s Expiry Date = %s\n",
 			info.DomainID, info.Domain, info.RegDate, info.ExpDate)
 		for j, record := range info.Records {
-			log.Printf("Record %d: Type = %s, Name = %s, TTL = %d, Value = %s, Priority = %d\n",
-				j, record.Type, record.Name, record.TTL, record.Value, record.Priority)
+			log.Printf("Record %d: Type = %s, Name = %s, TTL = %d, Value = %s, Priority = %d, Weight = %d, Port = %d\n",
+				j, record.Type, record.Name, record.TTL, record.Value, record.Priority, record.Weight, record.Port)
 		}
+
+		return info.Records
 	}
 }
 
 func TestGetDomainInfo(t *testing.T) {
-	TestLogin(t)
+	newTestServer(t,
+		freenomtest.Record{Type: "A", Name: "", TTL: 3600, Value: "123.123.123.123"},
+		freenomtest.Record{Type: "MX", Name: "mymail", TTL: 6666, Value: "maildomain.com", Priority: 10},
+	)
 
-	showRecords(freenomDomain, t)
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	records := showRecords(freenomDomain, t)
+	if 2 != len(records) {
+		t.Fatalf("expect 2 records, got %d", len(records))
+	}
+
+	if *records[1] != (DomainRecord{Type: "MX", Name: "mymail", TTL: 6666, Value: "maildomain.com", Priority: 10}) {
+		t.Errorf("unexpected record: %+v", *records[1])
+	}
+
+	if _, err := GetDomainInfo("not-mine.tk"); nil == err {
+		t.Error("GetDomainInfo should fail for unknown domain")
+	}
 }
 
 func TestAddRecord(t *testing.T) {
-	TestLogin(t)
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
 
 	var records []DomainRecord = []DomainRecord{
 		DomainRecord{
@@ -154,11 +217,31 @@ func TestAddRecord(t *testing.T) {
 
 	// 列出现有的记录列表
 	showRecords(freenomDomain, t)
+
+	d, _ := srv.Domain(freenomUser, freenomDomain)
+	if len(records) != len(d.Records) {
+		t.Errorf("expect %d records on server, got %d", len(records), len(d.Records))
+	}
+
+	// 页面中转义过的值（例如 NAPTR 中的 &quot;）解析后应与提交的值一致
+	for _, rec := range showRecords(freenomDomain, t) {
+		if "NAPTR" == rec.Type && records[6].Value != rec.Value {
+			t.Errorf("expect NAPTR value %q, got %q", records[6].Value, rec.Value)
+		}
+	}
+
+	if err := AddRecord(freenomDomain, []DomainRecord{{Type: "A", Name: "bad", TTL: 3600, Value: "::1"}}); nil == err {
+		t.Error("AddRecord should report the server's dnserror")
+	} else {
+		log.Println(err.Error())
+	}
+
+	if err := AddRecord(freenomDomain, nil); nil == err {
+		t.Error("AddRecord should fail with empty records")
+	}
 }
 
 func TestModifyRecord(t *testing.T) {
-	TestLogin(t)
-
 	const (
 		oldRecordType     string = "MX"
 		oldRecordName     string = "mymail"
@@ -173,6 +256,15 @@ func TestModifyRecord(t *testing.T) {
 		newRecordPriority int    = 99
 	)
 
+	srv := newTestServer(t,
+		freenomtest.Record{Type: "A", Name: "", TTL: 3600, Value: "123.123.123.123"},
+		freenomtest.Record{Type: oldRecordType, Name: oldRecordName, TTL: oldRecordTTL, Value: oldRecordValue, Priority: oldRecordPriority},
+	)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
 	// 列出原有的记录列表
 	showRecords(freenomDomain, t)
 
@@ -201,15 +293,109 @@ func TestModifyRecord(t *testing.T) {
 
 	// 列出现有的记录列表
 	showRecords(freenomDomain, t)
+
+	d, _ := srv.Domain(freenomUser, freenomDomain)
+	if 2 != len(d.Records) || (freenomtest.Record{Type: newRecordType, Name: newRecordName, TTL: newRecordTTL, Value: newRecordValue, Priority: newRecordPriority}) != d.Records[1] {
+		t.Errorf("record not modified on server: %+v", d.Records)
+	}
 }
 
-func TestDeleteRecordByIndex(t *testing.T) {
-	TestLogin(t)
+func TestRecordNoRetry(t *testing.T) {
+	srv := newTestServer(t, freenomtest.Record{Type: "A", Name: "", TTL: 3600, Value: "10.0.0.1"})
+
+	// 添加、修改及删除记录的请求返回 503，客户端不应重试
+	var posts, deletes int
+	failRefresh := false
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		if "delete" == q.Get("dnsaction") {
+			deletes++
+		}
+
+		if "POST" == req.Method && strings.HasSuffix(req.URL.Path, "/"+loginPath) ||
+			"delete" == q.Get("dnsaction") && 1 == deletes ||
+			failRefresh && "" != q.Get("managedns") && "" == q.Get("dnsaction") {
+			posts++
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		}
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
 
+	c, err := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd), WithTransport(rt), WithRetry(5, 0))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	record := DomainRecord{Type: RecordTypeA, Name: "www", TTL: 3600, Value: "10.0.0.2"}
+	if err = c.AddRecord(freenomDomain, []DomainRecord{record}); nil == err {
+		t.Error("expect AddRecord to fail")
+	}
+
+	if 1 != posts {
+		t.Errorf("AddRecord: expect 1 request, got %d", posts)
+	}
+
+	info, err := c.GetDomainInfo(freenomDomain)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	posts = 0
+	if err = c.ModifyRecord(freenomDomain, info.Records[0], &record); nil == err {
+		t.Error("expect ModifyRecord to fail")
+	}
+
+	if 1 != posts {
+		t.Errorf("ModifyRecord: expect 1 request, got %d", posts)
+	}
+
+	if err = c.DeleteRecord(freenomDomain, info.Records[0]); nil == err {
+		t.Error("expect DeleteRecord to fail")
+	}
+
+	if 1 != deletes {
+		t.Errorf("DeleteRecord: expect 1 request, got %d", deletes)
+	}
+
+	// 删除成功但刷新失败时删除缓存，不保留已删除的记录
+	failRefresh = true
+	if err = c.DeleteRecord(freenomDomain, info.Records[0]); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if _, ok := c.cachedDomain(freenomDomain); ok {
+		t.Error("expect cache to be invalidated after a failed refresh")
+	}
+
+	failRefresh = false
+	if info, err = c.GetDomainInfo(freenomDomain); nil != err || 0 != len(info.Records) {
+		t.Errorf("expect no records, got %+v %v", info, err)
+	}
+}
+
+func TestDeleteRecordByIndex(t *testing.T) {
 	const (
 		recordIndex int = 0
 	)
 
+	srv := newTestServer(t,
+		freenomtest.Record{Type: "A", Name: "", TTL: 3600, Value: "123.123.123.123"},
+		freenomtest.Record{Type: "TXT", Name: "mytxt", TTL: 9999, Value: "enter some text here"},
+	)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
 	// 列出原有的记录列表
 	showRecords(freenomDomain, t)
 
@@ -224,11 +410,18 @@ func TestDeleteRecordByIndex(t *testing.T) {
 
 	// 列出现有的记录列表
 	showRecords(freenomDomain, t)
+
+	d, _ := srv.Domain(freenomUser, freenomDomain)
+	if 1 != len(d.Records) || "TXT" != d.Records[0].Type {
+		t.Errorf("record not deleted on server: %+v", d.Records)
+	}
+
+	if err := DeleteRecordByIndex(freenomDomain, 5); nil == err {
+		t.Error("DeleteRecordByIndex should fail when index out of bounds")
+	}
 }
 
 func TestDeleteRecord(t *testing.T) {
-	TestLogin(t)
-
 	const (
 		recordType     string = "MX"
 		recordName     string = "mymail"
@@ -237,6 +430,14 @@ func TestDeleteRecord(t *testing.T) {
 		recordPriority int    = 10
 	)
 
+	srv := newTestServer(t,
+		freenomtest.Record{Type: recordType, Name: recordName, TTL: recordTTL, Value: recordValue, Priority: recordPriority},
+	)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
 	// 列出原有的记录列表
 	showRecords(freenomDomain, t)
 
@@ -258,18 +459,328 @@ func TestDeleteRecord(t *testing.T) {
 
 	// 列出现有的记录列表
 	showRecords(freenomDomain, t)
+
+	if d, _ := srv.Domain(freenomUser, freenomDomain); 0 != len(d.Records) {
+		t.Errorf("record not deleted on server: %+v", d.Records)
+	}
+}
+
+func TestSRVRecord(t *testing.T) {
+	srv := newTestServer(t,
+		freenomtest.Record{Type: "A", Name: "xmpp", TTL: 3600, Value: "127.0.0.1"},
+	)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	record := DomainRecord{
+		Type:     RecordTypeSRV,
+		Name:     "_xmpp-client._tcp",
+		TTL:      3600,
+		Value:    "xmpp.freenom-api.tk",
+		Priority: 5,
+		Weight:   10,
+		Port:     5222,
+	}
+
+	if err := AddRecord(freenomDomain, []DomainRecord{record}); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	records := showRecords(freenomDomain, t)
+	if 2 != len(records) || record != *records[1] {
+		t.Fatalf("SRV record not parsed: %+v", records)
+	}
+
+	modified := record
+	modified.Port = 5223
+	if err := ModifyRecord(freenomDomain, &record, &modified); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	d, _ := srv.Domain(freenomUser, freenomDomain)
+	if 2 != len(d.Records) || 5223 != d.Records[1].Port || 10 != d.Records[1].Weight || 5 != d.Records[1].Priority {
+		t.Fatalf("SRV record not modified on server: %+v", d.Records)
+	}
+
+	// 端口不同的记录视为不同的记录
+	if err := DeleteRecord(freenomDomain, &record); !errors.As(err, new(*DNSError)) {
+		t.Errorf("expect DNSError, got %v", err)
+	}
+
+	if err := DeleteRecord(freenomDomain, &modified); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if d, _ = srv.Domain(freenomUser, freenomDomain); 1 != len(d.Records) || "A" != d.Records[0].Type {
+		t.Errorf("SRV record not deleted on server: %+v", d.Records)
+	}
 }
 
 func TestRenewFreeDomain(t *testing.T) {
-	TestLogin(t)
+	srv := newTestServer(t)
+	srv.AddDomain(freenomUser, freenomtest.Domain{
+		Name:    "freenom-api.cf",
+		RegDate: time.Now().AddDate(-1, 0, 5),
+		ExpDate: time.Now().AddDate(0, 0, 5),
+	})
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	before, _ := srv.Domain(freenomUser, freenomDomain)
 
-	if domains, err := RenewFreeDomain(freenomDomain, 12); nil != err {
+	if renewals, err := RenewFreeDomain(freenomDomain, 12); nil != err {
 		t.Error(err.Error())
 		return
 	} else {
-		for domain, result := range domains {
-			log.Println(domain, result)
+		if 3 != len(renewals) {
+			t.Fatalf("expect 3 renewals, got %d", len(renewals))
 		}
+
+		for _, r := range renewals {
+			log.Printf("%+v", r)
+		}
+
+		if r := renewals[0]; freenomDomain != r.Domain || before.ID != r.DomainID || RenewalSuccess != r.Status ||
+			12 != r.Months || "" == r.OrderNumber || nil != r.Err || r.DaysLeft < 9 || r.DaysLeft > 10 {
+			t.Errorf("unexpected renewal %+v", r)
+		}
+
+		if r := renewals[1]; "freenom-api.ml" != r.Domain || RenewalNotDue != r.Status || 0 != r.Months || r.DaysLeft <= 14 {
+			t.Errorf("unexpected renewal %+v", r)
+		}
+
+		if r := renewals[2]; "freenom-api.cf" != r.Domain || RenewalNotSelected != r.Status || 0 != r.Months {
+			t.Errorf("unexpected renewal %+v", r)
+		}
+	}
+
+	after, _ := srv.Domain(freenomUser, freenomDomain)
+	if !after.ExpDate.Equal(before.ExpDate.AddDate(0, 12, 0)) {
+		t.Errorf("expiry date not extended: %s -> %s", before.ExpDate, after.ExpDate)
+	}
+
+	if _, err := RenewFreeDomain(freenomDomain, 13); nil == err {
+		t.Error("RenewFreeDomain should reject months greater than 12")
+	}
+}
+
+func TestRenewFreeDomainRejected(t *testing.T) {
+	srv := newTestServer(t)
+
+	// 模拟服务器不会拒绝可续期的域名，提交续期时换成 Freenom 的失败页面
+	failed, err := ioutil.ReadFile("internal/scrape/testdata/renewal_failed.html")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if "true" != req.URL.Query().Get("submitrenewals") {
+			return http.DefaultTransport.RoundTrip(req)
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			Body:       ioutil.NopCloser(bytes.NewReader(failed)),
+			Request:    req,
+		}, nil
+	})
+
+	c, err := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd), WithTransport(rt))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	renewals, err := c.RenewFreeDomain("", 12)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	var me *ManageError
+	if r := renewals[0]; RenewalFailed != r.Status || 12 != r.Months || !errors.As(r.Err, &me) ||
+		freenomDomain != me.Domain || !strings.Contains(me.Message, "not yet eligible") {
+		t.Errorf("unexpected renewal %+v", r)
+	}
+}
+
+func TestRenewFreeDomainRelogin(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddDomain(freenomUser, freenomtest.Domain{
+		Name:    "freenom-api.cf",
+		RegDate: time.Now().AddDate(-1, 0, 5),
+		ExpDate: time.Now().AddDate(0, 0, 5),
+	})
+
+	// 第一次提交续期前会话过期，提交时重新登录
+	submitted := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if "true" == req.URL.Query().Get("submitrenewals") {
+			if submitted++; 1 == submitted {
+				srv.ExpireSessions()
+			}
+		}
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	c, err := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd), WithTransport(rt))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	logins := srv.Logins()
+	renewals, err := c.RenewFreeDomain("", 12)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if logins+1 != srv.Logins() {
+		t.Errorf("expect one relogin, got %d", srv.Logins()-logins)
+	}
+
+	// 重新登录后的续期使用新的 token
+	for _, r := range renewals {
+		if RenewalNotDue != r.Status && RenewalSuccess != r.Status {
+			t.Errorf("unexpected renewal %+v", r)
+		}
+	}
+
+	if 3 != submitted {
+		t.Errorf("expect 3 submits including the replay, got %d", submitted)
+	}
+}
+
+func TestPlanRenewFreeDomain(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddDomain(freenomUser, freenomtest.Domain{
+		Name:    "freenom-api.cf",
+		RegDate: time.Now().AddDate(-1, 0, 5),
+		ExpDate: time.Now().AddDate(0, 0, 5),
+	})
+
+	submitted := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if "true" == req.URL.Query().Get("submitrenewals") {
+			submitted++
+		}
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	c, err := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd), WithTransport(rt))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if _, err = c.PlanRenewFreeDomain("", 6); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("expect ErrNotLoggedIn, got %v", err)
+	}
+
+	if err = c.Login(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	before, _ := srv.Domain(freenomUser, freenomDomain)
+
+	for _, tc := range []struct {
+		domain string
+		status []string
+	}{
+		{"", []string{RenewalPlanned, RenewalNotDue, RenewalPlanned}},
+		{"FREENOM-API.CF", []string{RenewalNotSelected, RenewalNotDue, RenewalPlanned}},
+	} {
+		renewals, err := c.PlanRenewFreeDomain(tc.domain, 6)
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		if len(tc.status) != len(renewals) {
+			t.Fatalf("%q: expect %d renewals, got %d", tc.domain, len(tc.status), len(renewals))
+		}
+
+		for i, r := range renewals {
+			months := 0
+			if RenewalPlanned == tc.status[i] {
+				months = 6
+			}
+
+			if tc.status[i] != r.Status || months != r.Months || "" == r.DomainID || nil != r.Err {
+				t.Errorf("%q: unexpected renewal %+v", tc.domain, r)
+			}
+		}
+	}
+
+	if _, err = c.PlanRenewFreeDomain("", 0); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("expect ErrInvalidPeriod, got %v", err)
+	}
+
+	if after, _ := srv.Domain(freenomUser, freenomDomain); 0 != submitted || !after.ExpDate.Equal(before.ExpDate) {
+		t.Errorf("plan should not renew, %d submitted", submitted)
+	}
+}
+
+func TestRenewPlanned(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddDomain(freenomUser, freenomtest.Domain{
+		Name:    "freenom-api.cf",
+		RegDate: time.Now().AddDate(-1, 0, 5),
+		ExpDate: time.Now().AddDate(0, 0, 5),
+	})
+
+	if err := RenewPlanned(nil); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("expect ErrNotLoggedIn, got %v", err)
+	}
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	renewals, err := PlanRenewFreeDomain("", 12)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 3 != len(renewals) {
+		t.Fatalf("expect 3 renewals, got %d", len(renewals))
+	}
+
+	before, _ := srv.Domain(freenomUser, freenomDomain)
+	skipped, _ := srv.Domain(freenomUser, "freenom-api.cf")
+
+	renewals[0].Months = 13
+	if err = RenewPlanned(renewals); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("expect ErrInvalidPeriod, got %v", err)
+	}
+
+	// 按域名修改月份数，跳过 freenom-api.cf
+	renewals[0].Months = 3
+	renewals[2].Status = RenewalNotSelected
+	if err = RenewPlanned(renewals); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if r := renewals[0]; RenewalSuccess != r.Status || 3 != r.Months || "" == r.OrderNumber {
+		t.Errorf("unexpected renewal %+v", r)
+	}
+
+	if after, _ := srv.Domain(freenomUser, freenomDomain); !after.ExpDate.Equal(before.ExpDate.AddDate(0, 3, 0)) {
+		t.Errorf("expiry date not extended by 3 months: %s -> %s", before.ExpDate, after.ExpDate)
+	}
+
+	if after, _ := srv.Domain(freenomUser, "freenom-api.cf"); !after.ExpDate.Equal(skipped.ExpDate) {
+		t.Errorf("skipped domain renewed: %s -> %s", skipped.ExpDate, after.ExpDate)
 	}
 }
 
@@ -278,6 +789,9 @@ func TestCheckFreeDomainPurchasable(t *testing.T) {
 		domainToCheck string = "freenom-api"
 	)
 
+	srv := newTestServer(t)
+	srv.Take("freenom-api.ga")
+
 	if domains, err := CheckFreeDomainPurchasable(domainToCheck); nil != err {
 		t.Error(err.Error())
 		return
@@ -285,5 +799,10 @@ func TestCheckFreeDomainPurchasable(t *testing.T) {
 		for i, domain := range domains {
 			log.Println(i, domain)
 		}
+
+		// freenom-api.tk/.ml 已属于测试账号，freenom-api.ga 已被他人注册
+		if 2 != len(domains) || "freenom-api.cf" != domains[0] || "freenom-api.gq" != domains[1] {
+			t.Errorf("unexpected available domains: %+v", domains)
+		}
 	}
 }
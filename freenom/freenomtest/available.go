@@ -0,0 +1,133 @@
+package freenomtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// paidDomain 收费域名价格
+type paidDomain struct {
+	tld       string
+	priceInt  string
+	priceCent string
+}
+
+// 收费域名列表（截取自 fn-available.php 的响应）
+var paidDomains = []paidDomain{
+	{".com", "8", "38"},
+	{".net", "6", "71"},
+	{".org", "9", "58"},
+	{".info", "8", "69"},
+}
+
+// freeDomainJSON fn-available.php 返回的免费域名
+type freeDomainJSON struct {
+	Status        string `json:"status"`
+	Domain        string `json:"domain"`
+	TLD           string `json:"tld"`
+	Currency      string `json:"currency"`
+	Type          string `json:"type"`
+	PriceInt      string `json:"price_int"`
+	PriceCent     string `json:"price_cent"`
+	ShowTopDomain int    `json:"show_top_domain"`
+	IsInCart      int    `json:"is_in_cart"`
+}
+
+// paidDomainJSON fn-available.php 返回的收费域名
+type paidDomainJSON struct {
+	Domain    string `json:"domain"`
+	TLD       string `json:"tld"`
+	PriceInt  string `json:"price_int"`
+	PriceCent string `json:"price_cent"`
+	Currency  string `json:"currency"`
+	Location  string `json:"location"`
+	IsInCart  int    `json:"is_in_cart"`
+}
+
+// availableJSON fn-available.php 的响应
+type availableJSON struct {
+	Status         string            `json:"status"`
+	MaximumReached int               `json:"maximum_reached"`
+	TopDomain      map[string]int    `json:"top_domain"`
+	FreeDomains    []*freeDomainJSON `json:"free_domains"`
+	PaidDomains    []*paidDomainJSON `json:"paid_domains"`
+}
+
+// registered 判断域名是否已被注册
+// 调用方需持有 s.mu
+func (s *Server) registered(domain string) bool {
+	if s.taken[strings.ToLower(domain)] {
+		return true
+	}
+
+	for _, a := range s.accounts {
+		if nil != a.findDomain(domain, "") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) handleAvailable(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.lookupSession(r)
+	r.ParseForm()
+	prefix := strings.ToLower(strings.TrimSpace(r.PostForm.Get("domain")))
+	tld := strings.ToLower(r.PostForm.Get("tld"))
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if "POST" != r.Method || "" == prefix || strings.Contains(prefix, ".") {
+		json.NewEncoder(w).Encode(map[string]string{"status": "ERROR"})
+		return
+	}
+
+	res := &availableJSON{
+		Status:    "OK",
+		TopDomain: map[string]int{"dont_show": 1},
+	}
+
+	for _, t := range freeTLDs {
+		if "" != tld && t != tld {
+			continue
+		}
+
+		status := "AVAILABLE"
+		if s.registered(prefix + t) {
+			status = "NOT AVAILABLE"
+		}
+
+		inCart := 0
+		if nil != sess && nil != sess.cartItem(prefix+t) {
+			inCart = 1
+		}
+
+		res.FreeDomains = append(res.FreeDomains, &freeDomainJSON{
+			Status:    status,
+			Domain:    prefix,
+			TLD:       t,
+			Currency:  "USD",
+			Type:      "FREE",
+			PriceInt:  "0",
+			PriceCent: "00",
+			IsInCart:  inCart,
+		})
+	}
+
+	for _, p := range paidDomains {
+		res.PaidDomains = append(res.PaidDomains, &paidDomainJSON{
+			Domain:    prefix,
+			TLD:       p.tld,
+			PriceInt:  p.priceInt,
+			PriceCent: p.priceCent,
+			Currency:  "USD",
+			Location:  "false",
+		})
+	}
+
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,456 @@
+package freenomtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CaptchaSiteKey 购物车页面中 reCAPTCHA v3 的站点密钥，与 Freenom 站点一致
+const CaptchaSiteKey string = "6LeRyJsUAAAAAOKmSY52HO3iBDz2QbXas2Q-kHjy"
+
+// reCAPTCHA v3 在购物车各页面上执行的动作
+const (
+	captchaActionConfDomains    string = "cart_confdomains_account"
+	captchaActionCheckout       string = "cart_checkout_account"
+	captchaActionSubmitCheckout string = "cart_submit_checkout_account"
+)
+
+// 加入购物车后的默认注册时长，与 Freenom 站点一致
+const defaultPeriod string = "3M"
+
+// CaptchaToken 返回模拟服务器接受的 reCAPTCHA 令牌
+// 模拟服务器不连接 Google，只检查令牌是否对应页面上执行的动作
+func CaptchaToken(action string) string {
+	return "freenomtest-" + action
+}
+
+// cartItem 购物车中的域名
+type cartItem struct {
+	domain      string // 小写的完整域名
+	price       string // 收费域名的价格，例如 8.38，免费域名为空
+	period      string // 注册时长，例如 3M
+	idShield    bool
+	forward     string   // URL 转发地址，为空时不转发
+	nameservers []string // 自定义域名服务器，为空时使用 Freenom 默认域名服务器
+}
+
+// periodMonths 注册时长（例如 12M）对应的月数，格式不正确或超出 1 至 12 个月时返回 0
+func periodMonths(period string) int {
+	m, err := strconv.Atoi(strings.TrimSuffix(period, "M"))
+	if nil != err || !strings.HasSuffix(period, "M") || m < 1 || m > 12 {
+		return 0
+	}
+
+	return m
+}
+
+// cartItem 查找会话购物车中的域名
+func (sess *session) cartItem(domain string) *cartItem {
+	for _, item := range sess.cart {
+		if strings.EqualFold(item.domain, domain) {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// paidPrice 收费域名后缀的价格，例如 8.38，不是收费域名后缀时返回空字符串
+func paidPrice(tld string) string {
+	for _, p := range paidDomains {
+		if strings.EqualFold(p.tld, tld) {
+			return p.priceInt + "." + p.priceCent
+		}
+	}
+
+	return ""
+}
+
+// isFreeTLD 判断是否为免费域名后缀
+func isFreeTLD(tld string) bool {
+	for _, t := range freeTLDs {
+		if strings.EqualFold(t, tld) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// handleAddToCart fn-additional.php，将域名加入购物车，收费域名按 paidDomains 中的价格加入
+func (s *Server) handleAddToCart(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	r.ParseForm()
+
+	prefix := strings.ToLower(strings.TrimSpace(r.PostForm.Get("domain")))
+	tld := strings.ToLower(r.PostForm.Get("tld"))
+	domain := prefix + tld
+
+	price := paidPrice(tld)
+	if "POST" != r.Method || "" == prefix || !isFreeTLD(tld) && "" == price || s.registered(domain) {
+		writeJSON(w, map[string]int{"available": 0})
+		return
+	}
+
+	if nil == sess.cartItem(domain) {
+		sess.cart = append(sess.cart, &cartItem{
+			domain:   domain,
+			price:    price,
+			period:   defaultPeriod,
+			idShield: true,
+		})
+	}
+
+	writeJSON(w, map[string]int{"available": 1})
+}
+
+// handleRemoveFromCart fn-remove.php，从购物车中删除域名，响应内容始终为空
+func (s *Server) handleRemoveFromCart(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	r.ParseForm()
+
+	if "POST" == r.Method {
+		domain := strings.TrimSpace(r.PostForm.Get("domain")) + r.PostForm.Get("tld")
+		for i, item := range sess.cart {
+			if strings.EqualFold(item.domain, domain) {
+				sess.cart = append(sess.cart[:i], sess.cart[i+1:]...)
+				break
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+}
+
+// handleCaptchaCheck cap_chk.php，记录会话通过了哪个动作的 reCAPTCHA 校验，响应内容始终为空
+func (s *Server) handleCaptchaCheck(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	r.ParseForm()
+
+	sess.captcha = ""
+	if token := r.PostForm.Get("token"); strings.HasPrefix(token, CaptchaToken("")) {
+		sess.captcha = strings.TrimPrefix(token, CaptchaToken(""))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+}
+
+// pricingJSON confdomain-pricing.php 返回的价格
+type pricingJSON struct {
+	Period   string `json:"period"`
+	Price    string `json:"price"`
+	Currency string `json:"currency"`
+}
+
+// domainPricingJSON confdomain-pricing.php 返回的域名价格表
+type domainPricingJSON struct {
+	DomainType string         `json:"domaintype"`
+	DomainName string         `json:"domainname"`
+	Pricing    []*pricingJSON `json:"pricing"`
+}
+
+// handleDomainPricing confdomain-pricing.php，返回购物车中域名各注册时长的价格，免费域名 1 至 12 个月均为 0
+// 收费域名每月的价格相同，便于测试
+func (s *Server) handleDomainPricing(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	r.ParseForm()
+
+	res := make(map[string]*domainPricingJSON)
+	for _, domain := range r.PostForm["domains[]"] {
+		item := sess.cartItem(domain)
+		if nil == item {
+			continue
+		}
+
+		price := item.price
+		if "" == price {
+			price = "0.00"
+		}
+
+		name := strings.ToUpper(domain)
+		p := &domainPricingJSON{
+			DomainType: "PAID", // 与 Freenom 站点一致，免费域名也返回 PAID
+			DomainName: name,
+		}
+
+		for m := 1; m <= 12; m++ {
+			p.Pricing = append(p.Pricing, &pricingJSON{
+				Period:   fmt.Sprintf("%dM", m),
+				Price:    price,
+				Currency: "USD",
+			})
+		}
+
+		res[name] = p
+	}
+
+	writeJSON(w, res)
+}
+
+// statusJSON confdomain-update.php 及 domainconfigure.php 的响应
+type statusJSON struct {
+	Status string            `json:"status"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// handleDomainUpdate confdomain-update.php，修改购物车中域名的注册时长或 ID Shield
+func (s *Server) handleDomainUpdate(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	r.ParseForm()
+
+	domain := strings.TrimSpace(r.PostForm.Get("domain"))
+	item := sess.cartItem(domain)
+	if "POST" != r.Method || nil == item {
+		writeJSON(w, &statusJSON{Status: "ERROR", Errors: map[string]string{domain: "Domain is not in your cart"}})
+		return
+	}
+
+	if period := r.PostForm.Get("period"); "" != period {
+		if 0 == periodMonths(period) {
+			writeJSON(w, &statusJSON{Status: "ERROR", Errors: map[string]string{domain + "_period": "Invalid period"}})
+			return
+		}
+
+		item.period = period
+	}
+
+	switch r.PostForm.Get("idshield") {
+	case "enabled":
+		item.idShield = true
+
+	case "disabled":
+		item.idShield = false
+	}
+
+	writeJSON(w, &statusJSON{Status: "OK"})
+}
+
+// handleDomainConfigure domainconfigure.php，保存购物车中域名的 URL 转发或域名服务器设置
+// 参数 data 为 JSON：{"域名": {"urlfwd": "...", "dn1": "...", "di1": "...", ...}}
+func (s *Server) handleDomainConfigure(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	r.ParseForm()
+
+	var data map[string]map[string]string
+	if err := json.Unmarshal([]byte(r.PostForm.Get("data")), &data); nil != err || "POST" != r.Method {
+		writeJSON(w, &statusJSON{Status: "ERROR", Errors: map[string]string{"data": "Invalid data"}})
+		return
+	}
+
+	errs := make(map[string]string)
+	for domain, fields := range data {
+		item := sess.cartItem(domain)
+		if nil == item {
+			errs[domain] = "Domain is not in your cart"
+			continue
+		}
+
+		if fwd := strings.TrimSpace(fields["urlfwd"]); "" != fwd && "http://" != fwd {
+			u, err := url.Parse(fwd)
+			if nil != err || ("http" != u.Scheme && "https" != u.Scheme) || "" == u.Host {
+				errs[domain+"_urlfwd"] = "Please enter a valid URL"
+			} else if strings.EqualFold(u.Hostname(), item.domain) {
+				errs[domain+"_urlfwd"] = "A domain can not be forwarded to itself"
+			} else {
+				item.forward = u.String()
+				item.nameservers = nil
+			}
+
+			continue
+		}
+
+		var hosts []string
+		for i := 1; i <= 5; i++ {
+			if ns := strings.TrimSpace(fields["dn"+strconv.Itoa(i)]); "" != ns {
+				hosts = append(hosts, strings.ToUpper(ns))
+			}
+		}
+
+		switch {
+		case 0 == len(hosts): // 使用 Freenom DNS
+
+		case len(hosts) < 2:
+			errs[domain+"_dn2"] = "You must enter at least 2 nameservers"
+
+		default:
+			item.nameservers = hosts
+			item.forward = ""
+		}
+	}
+
+	if 0 != len(errs) {
+		writeJSON(w, &statusJSON{Status: "ERROR", Errors: errs})
+		return
+	}
+
+	writeJSON(w, &statusJSON{Status: "OK"})
+}
+
+// handleCart cart.php 的各个页面
+func (s *Server) handleCart(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	a := s.account(sess)
+	if nil == a {
+		s.writeLoginPage(w, sess)
+		return
+	}
+
+	switch r.URL.Query().Get("a") {
+	case "confdomains":
+		if "POST" == r.Method {
+			s.handleSubmitConfDomains(w, r, sess)
+			return
+		}
+
+		s.writeConfDomainsPage(w, sess, "")
+
+	case "checkout":
+		s.handleCheckout(w, r, sess, a)
+
+	case "complete":
+		s.writeOrderCompletePage(w, sess)
+
+	case "empty":
+		sess.cart = nil
+		http.Redirect(w, r, "/cart.php?a=view", http.StatusFound)
+
+	default:
+		s.writeCartPage(w, sess, "")
+	}
+}
+
+// handleSubmitConfDomains 提交域名配置表单，成功后跳转到购物车页
+func (s *Server) handleSubmitConfDomains(w http.ResponseWriter, r *http.Request, sess *session) {
+	r.ParseForm()
+
+	if r.PostForm.Get("token") != sess.token {
+		s.writeConfDomainsPage(w, sess, "Invalid token")
+		return
+	}
+
+	if captchaActionConfDomains != sess.captcha {
+		s.writeConfDomainsPage(w, sess, "Please complete the captcha and try again.")
+		return
+	}
+
+	for _, item := range sess.cart {
+		period := r.PostForm.Get(strings.Replace(item.domain, ".", "_", 1) + "_period")
+		if "" == period {
+			continue
+		}
+
+		if 0 == periodMonths(period) {
+			s.writeConfDomainsPage(w, sess, "Invalid period for "+item.domain)
+			return
+		}
+
+		item.period = period
+	}
+
+	sess.captcha = ""
+	http.Redirect(w, r, "/cart.php?a=view", http.StatusFound)
+}
+
+// handleCheckout 提交结账表单，注册购物车中的所有域名后跳转到订单完成页
+func (s *Server) handleCheckout(w http.ResponseWriter, r *http.Request, sess *session, a *Account) {
+	r.ParseForm()
+
+	switch {
+	case "POST" != r.Method || r.PostForm.Get("token") != sess.token:
+		s.writeCartPage(w, sess, "Invalid token")
+		return
+
+	case captchaActionCheckout != sess.captcha ||
+		CaptchaToken(captchaActionSubmitCheckout) != r.PostForm.Get("captcha_tkn"):
+		s.writeCartPage(w, sess, "Please complete the captcha and try again.")
+		return
+
+	case "on" != r.PostForm.Get("accepttos"):
+		s.writeCartPage(w, sess, "You must accept our Terms of Service")
+		return
+
+	case 0 == len(sess.cart):
+		s.writeCartPage(w, sess, "Your shopping cart is empty")
+		return
+	}
+
+	for _, item := range sess.cart {
+		if s.registered(item.domain) {
+			s.writeCartPage(w, sess, item.domain+" is no longer available")
+			return
+		}
+
+		if 0 == periodMonths(item.period) {
+			s.writeCartPage(w, sess, "Invalid period for "+item.domain)
+			return
+		}
+	}
+
+	now := s.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, item := range sess.cart {
+		domainType := "Free"
+		if "" != item.price {
+			domainType = "Paid"
+		}
+
+		s.nextID++
+		d := &Domain{
+			Name:        item.domain,
+			ID:          strconv.FormatInt(s.nextID, 10),
+			RegDate:     today,
+			ExpDate:     today.AddDate(0, periodMonths(item.period), 0),
+			Status:      "Active",
+			Type:        domainType,
+			Nameservers: append([]string(nil), item.nameservers...),
+		}
+
+		if "" != item.forward {
+			d.Forwarding = &Forwarding{
+				URL:  item.forward,
+				Type: 1,
+			}
+		}
+
+		a.Domains = append(a.Domains, d)
+	}
+
+	s.nextID++
+	sess.order = strconv.FormatInt(s.nextID, 10)
+	sess.cart = nil
+	sess.captcha = ""
+
+	http.Redirect(w, r, "/cart.php?a=complete", http.StatusFound)
+}
@@ -0,0 +1,199 @@
+package freenomtest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 支持的 DNS 记录类型
+var recordTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CNAME": true,
+	"LOC":   true,
+	"MX":    true,
+	"NAPTR": true,
+	"RP":    true,
+	"TXT":   true,
+	"SRV":   true,
+}
+
+// validateRecord 校验 DNS 记录
+// 返回 错误信息，为空表示校验通过
+func validateRecord(rec *Record) string {
+	if !recordTypes[rec.Type] {
+		return fmt.Sprintf("Invalid record type %s", rec.Type)
+	}
+
+	if rec.TTL <= 0 {
+		return "Invalid TTL"
+	}
+
+	if "" == rec.Value {
+		return "Value can not be empty"
+	}
+
+	switch rec.Type {
+	case "A":
+		if ip := net.ParseIP(rec.Value); nil == ip || nil == ip.To4() {
+			return fmt.Sprintf("Invalid IPv4 address %s", rec.Value)
+		}
+
+	case "AAAA":
+		if ip := net.ParseIP(rec.Value); nil == ip || nil != ip.To4() {
+			return fmt.Sprintf("Invalid IPv6 address %s", rec.Value)
+		}
+
+	case "SRV":
+		if rec.Port < 1 || rec.Port > 65535 {
+			return fmt.Sprintf("Invalid port %d", rec.Port)
+		}
+
+		if rec.Weight < 0 || rec.Weight > 65535 || rec.Priority < 0 || rec.Priority > 65535 {
+			return "Invalid priority or weight"
+		}
+	}
+
+	return ""
+}
+
+// sameRecord 判断两条记录是否相同
+func sameRecord(a, b *Record) bool {
+	return strings.EqualFold(a.Type, b.Type) &&
+		strings.EqualFold(a.Name, b.Name) &&
+		strings.EqualFold(a.Value, b.Value) &&
+		a.Port == b.Port
+}
+
+// parseRecord 从表单中解析一条记录
+// 参数 prefix 形如 addrecord[0] 或 records[0]
+func parseRecord(form url.Values, prefix string) (rec Record, ok bool) {
+	if _, ok = form[prefix+"[type]"]; !ok {
+		return
+	}
+
+	rec.Type = strings.ToUpper(form.Get(prefix + "[type]"))
+	rec.Name = form.Get(prefix + "[name]")
+	rec.Value = form.Get(prefix + "[value]")
+	rec.TTL, _ = strconv.Atoi(form.Get(prefix + "[ttl]"))
+	rec.Priority, _ = strconv.Atoi(form.Get(prefix + "[priority]"))
+	rec.Weight, _ = strconv.Atoi(form.Get(prefix + "[weight]"))
+	rec.Port, _ = strconv.Atoi(form.Get(prefix + "[port]"))
+	return
+}
+
+func (s *Server) handleManageDNS(w http.ResponseWriter, r *http.Request, sess *session, a *Account) {
+	q := r.URL.Query()
+	d := a.findDomain(q.Get("managedns"), q.Get("domainid"))
+	if nil == d {
+		s.writePage(w, sess, "Manage Freenom DNS", `<section class="domainContent"><p>Domain not found</p></section>`)
+		return
+	}
+
+	r.ParseForm()
+
+	var errs []string
+	var success string
+
+	switch r.Form.Get("dnsaction") {
+	case "add":
+		if r.PostForm.Get("token") != sess.token {
+			errs = append(errs, "Invalid token")
+			break
+		}
+
+		var added []Record
+		for i := 0; ; i++ {
+			rec, ok := parseRecord(r.PostForm, fmt.Sprintf("addrecord[%d]", i))
+			if !ok {
+				break
+			}
+
+			if msg := validateRecord(&rec); "" != msg {
+				errs = append(errs, fmt.Sprintf("Record %d: %s", i+1, msg))
+				continue
+			}
+
+			for j := range d.Records {
+				if sameRecord(&d.Records[j], &rec) {
+					errs = append(errs, fmt.Sprintf("Record %d: Record already exists", i+1))
+					break
+				}
+			}
+
+			added = append(added, rec)
+		}
+
+		if 0 == len(errs) {
+			if 0 == len(added) {
+				errs = append(errs, "No records to add")
+				break
+			}
+
+			d.Records = append(d.Records, added...)
+			success = "Record added successfully"
+		}
+
+	case "modify":
+		if r.PostForm.Get("token") != sess.token {
+			errs = append(errs, "Invalid token")
+			break
+		}
+
+		var records []Record
+		for i := 0; ; i++ {
+			rec, ok := parseRecord(r.PostForm, fmt.Sprintf("records[%d]", i))
+			if !ok {
+				break
+			}
+
+			if msg := validateRecord(&rec); "" != msg {
+				errs = append(errs, fmt.Sprintf("Record %d: %s", i+1, msg))
+				continue
+			}
+
+			records = append(records, rec)
+		}
+
+		if 0 == len(errs) {
+			d.Records = records
+			success = "Record modified successfully"
+		}
+
+	case "delete":
+		ttl, _ := strconv.Atoi(q.Get("ttl"))
+		priority, _ := strconv.Atoi(q.Get("priority"))
+		weight, _ := strconv.Atoi(q.Get("weight"))
+		port, _ := strconv.Atoi(q.Get("port"))
+		target := Record{
+			Type:     strings.ToUpper(q.Get("records")),
+			Name:     q.Get("name"),
+			TTL:      ttl,
+			Value:    q.Get("value"),
+			Priority: priority,
+			Weight:   weight,
+			Port:     port,
+		}
+
+		found := false
+		for i := range d.Records {
+			if sameRecord(&d.Records[i], &target) && d.Records[i].TTL == target.TTL {
+				d.Records = append(d.Records[:i], d.Records[i+1:]...)
+				found = true
+				break
+			}
+		}
+
+		if found {
+			success = "Record deleted successfully"
+		} else {
+			errs = append(errs, "Record not found")
+		}
+	}
+
+	s.writeManageDNSPage(w, sess, d, errs, success)
+}
@@ -0,0 +1,227 @@
+package freenomtest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// handleDomainDetails 域名管理页 clientarea.php?action=domaindetails&id=ID
+// 参数 modop=custom&a= 对应各项管理工具
+func (s *Server) handleDomainDetails(w http.ResponseWriter, r *http.Request, sess *session, a *Account) {
+	q := r.URL.Query()
+	d := a.findDomain("", q.Get("id"))
+	if nil == d {
+		s.writePage(w, sess, "Domain Details", `<section class="domainContent"><div class="alert alert-danger">Domain not found</div></section>`)
+		return
+	}
+
+	switch q.Get("a") {
+	case "urlforwarding":
+		s.handleURLForwarding(w, r, sess, d)
+
+	case "nameservers":
+		s.handleNameservers(w, r, sess, d)
+
+	case "registerns":
+		s.handleRegisterNameservers(w, r, sess, d)
+
+	default:
+		s.writeDomainDetailsPage(w, sess, d)
+	}
+}
+
+func (s *Server) handleURLForwarding(w http.ResponseWriter, r *http.Request, sess *session, d *Domain) {
+	if "POST" != r.Method {
+		s.writeURLForwardingPage(w, sess, d, "", "")
+		return
+	}
+
+	r.ParseForm()
+	if r.PostForm.Get("token") != sess.token {
+		s.writeURLForwardingPage(w, sess, d, "Invalid token", "")
+		return
+	}
+
+	switch r.PostForm.Get("mode") {
+	case "dns":
+		d.Forwarding = nil
+
+	case "forward":
+		u, err := url.Parse(r.PostForm.Get("url"))
+		if nil != err || ("http" != u.Scheme && "https" != u.Scheme) || "" == u.Host {
+			s.writeURLForwardingPage(w, sess, d, "Please enter a valid URL", "")
+			return
+		}
+
+		if strings.EqualFold(u.Hostname(), d.Name) {
+			s.writeURLForwardingPage(w, sess, d, "A domain can not be forwarded to itself", "")
+			return
+		}
+
+		typ, _ := strconv.Atoi(r.PostForm.Get("forward_type"))
+		if 1 != typ && 2 != typ {
+			s.writeURLForwardingPage(w, sess, d, "Invalid forwarding type", "")
+			return
+		}
+
+		d.Forwarding = &Forwarding{
+			URL:             u.String(),
+			Type:            typ,
+			Title:           strings.TrimSpace(r.PostForm.Get("title")),
+			MetaDescription: strings.TrimSpace(r.PostForm.Get("meta_description")),
+			MetaKeywords:    strings.TrimSpace(r.PostForm.Get("meta_keywords")),
+		}
+
+	default:
+		s.writeURLForwardingPage(w, sess, d, "Invalid mode", "")
+		return
+	}
+
+	s.writeURLForwardingPage(w, sess, d, "", "Changes Saved Successfully!")
+}
+
+func (s *Server) handleNameservers(w http.ResponseWriter, r *http.Request, sess *session, d *Domain) {
+	if "POST" != r.Method {
+		s.writeNameserversPage(w, sess, d, "", "")
+		return
+	}
+
+	r.ParseForm()
+	if r.PostForm.Get("token") != sess.token || "savens" != r.PostForm.Get("sub") {
+		s.writeNameserversPage(w, sess, d, "Invalid token", "")
+		return
+	}
+
+	switch r.PostForm.Get("nschoice") {
+	case "default":
+		d.Nameservers = nil
+
+	case "custom":
+		var hosts []string
+		for i := 1; i <= 5; i++ {
+			if ns := strings.TrimSpace(r.PostForm.Get("ns" + strconv.Itoa(i))); "" != ns {
+				hosts = append(hosts, strings.ToUpper(ns))
+			}
+		}
+
+		if len(hosts) < 2 {
+			s.writeNameserversPage(w, sess, d, "You must enter at least 2 nameservers", "")
+			return
+		}
+
+		d.Nameservers = hosts
+
+	default:
+		s.writeNameserversPage(w, sess, d, "Invalid nameserver choice", "")
+		return
+	}
+
+	s.writeNameserversPage(w, sess, d, "", "Changes Saved Successfully!")
+}
+
+// 注册局不接受的内网地址段
+var privateNets = func() (nets []*net.IPNet) {
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "169.254.0.0/16", "fc00::/7", "fe80::/10"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+
+	return
+}()
+
+// publicIP 校验 IP 地址是否为公网地址
+// 返回 错误信息，为空表示校验通过
+func publicIP(s string) string {
+	ip := net.ParseIP(s)
+	if nil == ip {
+		return fmt.Sprintf("Invalid IP address %s", s)
+	}
+
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Sprintf("The IP address %s is not a public address", s)
+	}
+
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return fmt.Sprintf("The IP address %s is not a public address", s)
+		}
+	}
+
+	return ""
+}
+
+func (s *Server) handleRegisterNameservers(w http.ResponseWriter, r *http.Request, sess *session, d *Domain) {
+	if "POST" != r.Method {
+		s.writeRegisterNameserversPage(w, sess, d, "", "")
+		return
+	}
+
+	r.ParseForm()
+	if r.PostForm.Get("token") != sess.token {
+		s.writeRegisterNameserversPage(w, sess, d, "Invalid token", "")
+		return
+	}
+
+	prefix := strings.TrimSpace(r.PostForm.Get("ns"))
+	if "" == prefix || strings.Contains(prefix, " ") {
+		s.writeRegisterNameserversPage(w, sess, d, "Please enter a valid nameserver", "")
+		return
+	}
+
+	host := strings.ToUpper(prefix + "." + d.Name)
+	index := -1
+	for i := range d.GlueRecords {
+		if d.GlueRecords[i].Host == host {
+			index = i
+			break
+		}
+	}
+
+	switch r.PostForm.Get("sub") {
+	case "save":
+		ip := strings.TrimSpace(r.PostForm.Get("ipaddress"))
+		if msg := publicIP(ip); "" != msg {
+			s.writeRegisterNameserversPage(w, sess, d, msg, "")
+			return
+		}
+
+		if index >= 0 {
+			s.writeRegisterNameserversPage(w, sess, d, fmt.Sprintf("Nameserver %s already exists", host), "")
+			return
+		}
+
+		d.GlueRecords = append(d.GlueRecords, GlueRecord{Host: host, IP: ip})
+		s.writeRegisterNameserversPage(w, sess, d, "", "Nameserver registered successfully")
+
+	case "modify":
+		ip := strings.TrimSpace(r.PostForm.Get("newipaddress"))
+		if msg := publicIP(ip); "" != msg {
+			s.writeRegisterNameserversPage(w, sess, d, msg, "")
+			return
+		}
+
+		if index < 0 || d.GlueRecords[index].IP != strings.TrimSpace(r.PostForm.Get("currentipaddress")) {
+			s.writeRegisterNameserversPage(w, sess, d, fmt.Sprintf("Nameserver %s with the current IP address not found", host), "")
+			return
+		}
+
+		d.GlueRecords[index].IP = ip
+		s.writeRegisterNameserversPage(w, sess, d, "", "Nameserver modified successfully")
+
+	case "delete":
+		if index < 0 {
+			s.writeRegisterNameserversPage(w, sess, d, fmt.Sprintf("Nameserver %s not found", host), "")
+			return
+		}
+
+		d.GlueRecords = append(d.GlueRecords[:index], d.GlueRecords[index+1:]...)
+		s.writeRegisterNameserversPage(w, sess, d, "", "Nameserver deleted successfully")
+
+	default:
+		s.writeRegisterNameserversPage(w, sess, d, "Invalid request", "")
+	}
+}
@@ -0,0 +1,539 @@
+package freenomtest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 与 Freenom 页面一致的 HTML 转义（双引号转义为 &quot;）
+var htmlEscaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&quot;",
+	`'`, "&#39;",
+)
+
+// esc 转义 HTML
+func esc(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// writePage 输出完整页面，已登录时页头带有 Hello 标记
+// 调用方需持有 s.mu
+func (s *Server) writePage(w http.ResponseWriter, sess *session, title, body string) {
+	var header string
+	if a := s.account(sess); nil != a {
+		header = fmt.Sprintf(`<ul class="nav navbar-nav navbar-right">
+				<li class="dropdown">
+					<a href="#" class="dropdown-toggle" data-toggle="dropdown">
+						<span class="hidden-sm">Hello %s <i class="fa fa-angle-down"></i></span>
+					</a>
+					<ul class="dropdown-menu">
+						<li><a href="clientarea.php?action=details">Edit Account Details</a></li>
+						<li><a href="clientarea.php?action=invoices">My Invoices</a></li>
+						<li><a href="logout.php">Logout</a></li>
+					</ul>
+				</li>
+			</ul>`, esc(a.Name))
+	} else {
+		header = `<ul class="nav navbar-nav navbar-right"><li><a href="clientarea.php">Sign in</a></li></ul>`
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta http-equiv="content-type" content="text/html; charset=utf-8" />
+    <title>%s - Freenom</title>
+    <base href="/" />
+  </head>
+  <body>
+    <header>
+      <nav class="navbar">
+        %s
+      </nav>
+    </header>
+<section class="pageHeader">
+    <h1 class="primaryFontColor">%s</h1>
+</section>
+%s
+  </body>
+</html>
+`, esc(title), header, esc(title), body)
+}
+
+// writeLoginPage 输出登录页
+func (s *Server) writeLoginPage(w http.ResponseWriter, sess *session) {
+	s.writePage(w, sess, "Client Area", fmt.Sprintf(`<section class="loginContent">
+	<form method="post" action="dologin.php" class="form-stacked">
+		<input type="hidden" name="token" value="%s" />
+		<input type="email" name="username" class="form-control" placeholder="Email Address" />
+		<input type="password" name="password" class="form-control" placeholder="Password" />
+		<input type="submit" class="largeBtn primaryColor pullRight" value="Login" />
+	</form>
+</section>`, sess.token))
+}
+
+// writeHomePage 输出已登录的用户中心首页
+func (s *Server) writeHomePage(w http.ResponseWriter, sess *session) {
+	s.writePage(w, sess, "Client Area", fmt.Sprintf(`<section class="clientHome">
+	<p>Welcome back</p>
+	<form method="post" action="domains.php" class="domainSearch">
+		<input type="hidden" name="token" value="%s" />
+		<input type="text" name="domain" placeholder="Find a new domain" />
+		<input type="submit" class="smallBtn primaryColor" value="Check Availability" />
+	</form>
+</section>`, sess.token))
+}
+
+// writeDomainsPage 输出域名列表页的第 page 页（从 1 开始）
+func (s *Server) writeDomainsPage(w http.ResponseWriter, sess *session, a *Account, page int) {
+	domains := a.Domains
+	pages := 1
+	if s.DomainsPerPage > 0 && len(domains) > s.DomainsPerPage {
+		pages = (len(domains) + s.DomainsPerPage - 1) / s.DomainsPerPage
+	}
+
+	if page < 1 {
+		page = 1
+	} else if page > pages {
+		page = pages
+	}
+
+	if pages > 1 {
+		end := page * s.DomainsPerPage
+		if end > len(domains) {
+			end = len(domains)
+		}
+
+		domains = domains[(page-1)*s.DomainsPerPage : end]
+	}
+
+	var rows strings.Builder
+	for _, d := range domains {
+		fmt.Fprintf(&rows, `
+			<tr>
+				<td class="second"><a href="http://%s/" target="_blank">%s <i class="fa fa-external-link"></i></a></td>
+				<td class="third">%s</td>
+				<td class="fourth">%s</td>
+				<td class="fifth"><span class="text%s">%s</span></td>
+				<td class="sixth">%s</td>
+				<td class="seventh"><a class="smallBtn whiteBtn pullRight" href="clientarea.php?action=domaindetails&id=%s">Manage Domain <i class="fa fa-cog"></i></a></td>
+			</tr>`,
+			esc(d.Name), esc(d.Name), d.RegDate.Format("2006-01-02"), d.ExpDate.Format("2006-01-02"),
+			statusColor(d.Status), esc(d.Status), esc(d.Type), d.ID)
+	}
+
+	s.writePage(w, sess, "My Domains", fmt.Sprintf(`<section class="domainContent">
+	<table class="table table-striped table-bordered">
+		<thead>
+			<tr>
+				<th>Domain</th>
+				<th>Registration Date</th>
+				<th>Expiry date</th>
+				<th>Status</th>
+				<th>Type</th>
+				<th>&nbsp;</th>
+			</tr>
+		</thead>
+		<tbody>%s
+		</tbody>
+	</table>
+	%s
+</section>`, rows.String(), pagination(page, pages)))
+}
+
+// pagination 输出域名列表页的翻页链接
+func pagination(page, pages int) string {
+	if pages <= 1 {
+		return ""
+	}
+
+	prev := `<li class="previous disabled"><a href="#">&laquo; Previous Page</a></li>`
+	if page > 1 {
+		prev = fmt.Sprintf(`<li class="previous"><a href="clientarea.php?action=domains&amp;page=%d">&laquo; Previous Page</a></li>`, page-1)
+	}
+
+	next := `<li class="next disabled"><a href="#">Next Page &raquo;</a></li>`
+	if page < pages {
+		next = fmt.Sprintf(`<li class="next"><a href="clientarea.php?action=domains&amp;page=%d">Next Page &raquo;</a></li>`, page+1)
+	}
+
+	return fmt.Sprintf(`<p class="pageInfo">Page %d of %d</p>
+	<ul class="pagination">
+		%s
+		%s
+	</ul>`, page, pages, prev, next)
+}
+
+// statusColor 状态对应的样式颜色
+func statusColor(status string) string {
+	if strings.EqualFold("Active", status) {
+		return "green"
+	}
+
+	return "red"
+}
+
+// writeManageDNSPage 输出 DNS 记录管理页
+func (s *Server) writeManageDNSPage(w http.ResponseWriter, sess *session, d *Domain, errs []string, success string) {
+	var msgs strings.Builder
+	if 0 != len(errs) || "" != success {
+		msgs.WriteString(`<ul class="dnsMessages">`)
+		for _, e := range errs {
+			fmt.Fprintf(&msgs, `<li class="dnserror">%s</li>`, esc(e))
+		}
+
+		if "" != success {
+			fmt.Fprintf(&msgs, `<li class="dnssuccess">%s</li>`, esc(success))
+		}
+
+		msgs.WriteString(`</ul>`)
+	}
+
+	var rows strings.Builder
+	for i, rec := range d.Records {
+		var extra, weight, port string
+		switch rec.Type {
+		case "MX":
+			extra = fmt.Sprintf(`<input type="text" name="records[%d][priority]" value="%d" class="smallInput" />`, i, rec.Priority)
+
+		case "SRV":
+			extra = fmt.Sprintf(`<input type="text" name="records[%d][priority]" value="%d" class="smallInput" /><input type="text" name="records[%d][weight]" value="%d" class="smallInput" /><input type="text" name="records[%d][port]" value="%d" class="smallInput" />`,
+				i, rec.Priority, i, rec.Weight, i, rec.Port)
+			weight = strconv.Itoa(rec.Weight)
+			port = strconv.Itoa(rec.Port)
+		}
+
+		fmt.Fprintf(&rows, `
+			<tr class="%s">
+				<td class="type_column"><input type="hidden" name="records[%d][line]" value="" /><input type="hidden" name="records[%d][type]" value="%s" /><strong>%s</strong></td>
+				<td class="name_column"><input type="text" name="records[%d][name]" value="%s" class="smallInput" /></td>
+				<td class="ttl_column"><input type="text" name="records[%d][ttl]" value="%d" class="smallInput" /></td>
+				<td class="value_column"><input type="text" name="records[%d][value]" value="%s" class="smallInput" />%s</td>
+				<td class="delete_column"><button type="button" class="smallBtn redBtn" onclick="window.location='clientarea.php?managedns=%s&domainid=%s&page=&records=%s&dnsaction=delete&name=%s&value=%s&line=&ttl=%d&priority=%d&weight=%s&port=%s'">Delete</button></td>
+			</tr>`,
+			[]string{"even", "odd"}[i%2],
+			i, i, esc(rec.Type), esc(rec.Type),
+			i, esc(rec.Name),
+			i, rec.TTL,
+			i, esc(rec.Value), extra,
+			esc(d.Name), d.ID, esc(rec.Type), esc(rec.Name), esc(rec.Value), rec.TTL, rec.Priority, weight, port)
+	}
+
+	s.writePage(w, sess, "Manage Freenom DNS", fmt.Sprintf(`<section class="domainContent">
+	%s
+	<form id="recordslistform" method="post" action="clientarea.php?managedns=%s&domainid=%s">
+		<input type="hidden" name="token" value="%s" />
+		<input type="hidden" name="dnsaction" value="modify" />
+		<table class="table table-striped">
+			<thead>
+				<tr><th>Type</th><th>Name</th><th>TTL</th><th>Target</th><th>&nbsp;</th></tr>
+			</thead>
+			<tbody>%s
+			</tbody>
+		</table>
+	</form>
+</section>`, msgs.String(), esc(d.Name), d.ID, sess.token, rows.String()))
+}
+
+// writeRenewalsPage 输出可续期域名列表页
+func (s *Server) writeRenewalsPage(w http.ResponseWriter, sess *session, a *Account) {
+	var rows strings.Builder
+	for _, d := range a.Domains {
+		days := s.daysLeft(d)
+		color := "green"
+		if days <= 14 {
+			color = "red"
+		}
+
+		fmt.Fprintf(&rows, `<tr><td>%s</td><td>%s</td><td>Minimum Advance Renewal is 14 Days for Free Domains<span class="text%s">%d Days</span></td><td><a class="smallBtn greenBtn pullRight" href="domains.php?a=renewdomain&domain=%s">Renew This Domain</a></td></tr>
+`, esc(d.Name), esc(d.Status), color, days, d.ID)
+	}
+
+	s.writePage(w, sess, "Domain Renewals", fmt.Sprintf(`<section class="renewalContent">
+	<table class="table table-striped table-bordered">
+		<thead>
+			<tr><th>Domain</th><th>Status</th><th>Days Until Expiry</th><th>&nbsp;</th></tr>
+		</thead>
+		<tbody>
+%s		</tbody>
+	</table>
+</section>`, rows.String()))
+}
+
+// writeRenewSuccessPage 输出续期成功页
+func (s *Server) writeRenewSuccessPage(w http.ResponseWriter, sess *session, orderNumber string) {
+	s.writePage(w, sess, "Renewal Complete", fmt.Sprintf(`<section class="completedOrder">
+	<p>Thank you for your order. You will receive a confirmation email shortly.</p>
+	<div class="cartbox">
+		<p align="center"><strong>Your Order Number is: %s</strong></p>
+	</div>
+</section>`, orderNumber))
+}
+
+// writeRenewFailedPage 输出续期失败页
+func (s *Server) writeRenewFailedPage(w http.ResponseWriter, sess *session, msg string) {
+	s.writePage(w, sess, "Order Confirmation", fmt.Sprintf(`<section class="completedOrder">
+	<div class="alert alert-danger">%s</div>
+</section>`, esc(msg)))
+}
+
+// alertsHTML 输出错误或成功提示
+func alertsHTML(errMsg, success string) string {
+	var b strings.Builder
+	if "" != errMsg {
+		fmt.Fprintf(&b, `<div class="alert alert-danger">%s</div>`, esc(errMsg))
+	}
+
+	if "" != success {
+		fmt.Fprintf(&b, `<div class="alert alert-success">%s</div>`, esc(success))
+	}
+
+	return b.String()
+}
+
+// detailsURL 域名管理页中各项管理工具的地址
+func detailsURL(d *Domain, action string) string {
+	return fmt.Sprintf("clientarea.php?action=domaindetails&amp;id=%s&amp;modop=custom&amp;a=%s", d.ID, action)
+}
+
+// writeDomainDetailsPage 输出域名管理页
+func (s *Server) writeDomainDetailsPage(w http.ResponseWriter, sess *session, d *Domain) {
+	s.writePage(w, sess, "Managing "+d.Name, fmt.Sprintf(`<section class="domainContent">
+	<ul class="nav nav-tabs">
+		<li><a href="%s">URL Forwarding</a></li>
+		<li><a href="%s">Nameservers</a></li>
+		<li><a href="%s">Register a name server name</a></li>
+	</ul>
+</section>`, detailsURL(d, "urlforwarding"), detailsURL(d, "nameservers"), detailsURL(d, "registerns")))
+}
+
+// writeRegisterNameserversPage 输出注册域名服务器页
+func (s *Server) writeRegisterNameserversPage(w http.ResponseWriter, sess *session, d *Domain, errMsg, success string) {
+	var rows strings.Builder
+	for _, g := range d.GlueRecords {
+		fmt.Fprintf(&rows, `
+			<tr><td class="host">%s</td><td class="ip">%s</td></tr>`, esc(g.Host), esc(g.IP))
+	}
+
+	s.writePage(w, sess, "Register Nameservers", fmt.Sprintf(`<section class="domainContent">
+	%s
+	<table class="table glueRecords">
+		<thead><tr><th>Nameserver</th><th>IP Address</th></tr></thead>
+		<tbody>%s
+		</tbody>
+	</table>
+	<form method="post" class="form-horizontal registerNameserver" action="%s">
+		<input type="hidden" name="token" value="%s" />
+		<input type="hidden" name="sub" value="save" />
+		<input type="text" name="ns" value="" /> .%s
+		<input type="text" name="ipaddress" value="" />
+		<input type="submit" value="Save" />
+	</form>
+</section>`, alertsHTML(errMsg, success), rows.String(), detailsURL(d, "registerns"), sess.token, esc(d.Name)))
+}
+
+// writeNameserversPage 输出域名服务器设置页
+func (s *Server) writeNameserversPage(w http.ResponseWriter, sess *session, d *Domain, errMsg, success string) {
+	hosts := d.Nameservers
+	defaultChecked, customChecked := "", ` checked="checked"`
+	if 0 == len(hosts) {
+		hosts = DefaultNameservers
+		defaultChecked, customChecked = ` checked="checked"`, ""
+	}
+
+	var inputs strings.Builder
+	for i := 0; i < 5; i++ {
+		var ns string
+		if i < len(hosts) {
+			ns = hosts[i]
+		}
+
+		fmt.Fprintf(&inputs, `
+		<input type="text" name="ns%d" value="%s" class="form-control" />`, i+1, esc(ns))
+	}
+
+	s.writePage(w, sess, "Nameservers", fmt.Sprintf(`<section class="domainContent">
+	%s
+	<form method="post" class="form-horizontal nameservers" action="%s">
+		<input type="hidden" name="token" value="%s" />
+		<input type="hidden" name="sub" value="savens" />
+		<label><input type="radio" name="nschoice" value="default"%s /> Use default nameservers (Freenom Nameservers)</label>
+		<label><input type="radio" name="nschoice" value="custom"%s /> Use custom nameservers (enter below)</label>%s
+		<input type="submit" value="Change Nameservers" />
+	</form>
+</section>`, alertsHTML(errMsg, success), detailsURL(d, "nameservers"), sess.token,
+		defaultChecked, customChecked, inputs.String()))
+}
+
+// writeURLForwardingPage 输出 URL 转发设置页
+func (s *Server) writeURLForwardingPage(w http.ResponseWriter, sess *session, d *Domain, errMsg, success string) {
+	fwd := d.Forwarding
+	dnsChecked, forwardChecked := " checked", ""
+	if nil != fwd {
+		dnsChecked, forwardChecked = "", " checked"
+	} else {
+		fwd = &Forwarding{Type: 1}
+	}
+
+	selected := func(typ int) string {
+		if typ == fwd.Type {
+			return ` selected="selected"`
+		}
+
+		return ""
+	}
+
+	s.writePage(w, sess, "URL Forwarding", fmt.Sprintf(`<section class="domainContent">
+	%s
+	<form method="post" class="form-horizontal urlForwarding" action="%s">
+		<input type="hidden" name="token" value="%s" />
+		<label><input type="radio" name="mode" value="dns"%s /> Use Freenom DNS Service</label>
+		<label><input type="radio" name="mode" value="forward"%s /> Use URL Forwarding</label>
+		<input type="url" name="url" value="%s" />
+		<select name="forward_type">
+			<option value="1"%s>Redirect (301)</option>
+			<option value="2"%s>Frame (cloaking)</option>
+		</select>
+		<input type="text" name="title" value="%s" />
+		<textarea name="meta_description">%s</textarea>
+		<input type="text" name="meta_keywords" value="%s" />
+		<input type="submit" name="save" value="Save Changes" />
+	</form>
+</section>`, alertsHTML(errMsg, success), detailsURL(d, "urlforwarding"), sess.token,
+		dnsChecked, forwardChecked, esc(fwd.URL), selected(1), selected(2),
+		esc(fwd.Title), esc(fwd.MetaDescription), esc(fwd.MetaKeywords)))
+}
+
+// captchaScript 页面加载完成后执行 reCAPTCHA 并提交到 cap_chk.php 的脚本，与 Freenom 站点一致
+func captchaScript(action string) string {
+	return fmt.Sprintf(`<script src="https://www.google.com/recaptcha/api.js?render=%s"></script>
+<script>
+    grecaptcha.ready(function() {
+        grecaptcha.execute('%s', { action: '%s' }).then(function(token) {
+            $.post( "includes/custom/cap_chk.php", { token: token } );
+        });
+    });
+</script>`, CaptchaSiteKey, CaptchaSiteKey, action)
+}
+
+// writeConfDomainsPage 输出购物车中的域名配置页
+func (s *Server) writeConfDomainsPage(w http.ResponseWriter, sess *session, errMsg string) {
+	var rows, idprot strings.Builder
+	for i, item := range sess.cart {
+		key := strings.Replace(item.domain, ".", "_", 1)
+		fmt.Fprintf(&rows, `
+    <tr>
+        <td><span class="tableDomain">%s &nbsp;&nbsp;<i class="fa fa-minus-circle"></i></span></td>
+        <td class="usage">
+            <input id="%s_urlfwd" class="forward_input" type="text" name="" value="http://" />
+            <input id="%s_dn1" class="dnsname_input" type="text" name="" value="" />
+            <input id="%s_dn2" class="dnsname_input" type="text" name="" value="" />
+        </td>
+        <td class="right period"><span id="%s_period_text" initial="%s"> %d months </span></td>
+    </tr>`, esc(item.domain), esc(item.domain), esc(item.domain), esc(item.domain),
+			esc(key), esc(item.period), periodMonths(item.period))
+
+		state := "off"
+		if item.idShield {
+			state = "on"
+		}
+
+		fmt.Fprintf(&idprot, `
+<input type="hidden" id="%s_idprot" name="idprotection[%d]" value="%s">`, esc(key), i, state)
+	}
+
+	var ns strings.Builder
+	for i := 0; i < 5; i++ {
+		var host string
+		if i < len(DefaultNameservers) {
+			host = strings.ToLower(DefaultNameservers[i])
+		}
+
+		fmt.Fprintf(&ns, `
+<input type="hidden" name="domainns%d" value="%s">`, i+1, host)
+	}
+
+	s.writePage(w, sess, "Shopping Cart", fmt.Sprintf(`%s
+<section class="domainConfiguration">
+%s
+<form method="post" action="/cart.php?a=confdomains" class="domain-configuration-table" data-domain-configuration-form>
+<input type="hidden" name="token" value="%s" />
+<input type="hidden" name="update" value="true" />
+<table class="table table-bordered" id="domainconfig">
+    <thead><tr><th class="domain">Domain</th><th class="usage">Use your new domain</th><th class="period right">Period</th></tr></thead>
+    <tbody>%s
+    </tbody>
+</table>%s%s
+<input class="largeBtn primaryColor pull-right" id="configure_submit_button" type="button" value="Continue">
+</form>
+</section>`, captchaScript(captchaActionConfDomains), alertsHTML(errMsg, ""), sess.token,
+		rows.String(), idprot.String(), ns.String()))
+}
+
+// writeCartPage 输出购物车页
+func (s *Server) writeCartPage(w http.ResponseWriter, sess *session, errMsg string) {
+	var rows strings.Builder
+	var total float64
+	for i, item := range sess.cart {
+		price := "<strike>$9.95USD</strike> $0.00USD"
+		if "" != item.price {
+			price = "$" + item.price + "USD"
+			p, _ := strconv.ParseFloat(item.price, 64)
+			total += p
+		}
+
+		fmt.Fprintf(&rows, `
+                <tr class="carttableproduct"><td class="tableproduct">
+                Domain Registration - %s
+                <a href="#" onclick="removeItem('d','%d');return false" class="cartremove"><i class="fa fa-minus-circle"></i></a>
+                </td><td class="pricing textright">%s</td></tr>`, esc(item.domain), i, price)
+	}
+
+	s.writePage(w, sess, "Shopping Cart", fmt.Sprintf(`%s
+<section class="contentCart">
+%s
+<form method="post" action="/cart.php?a=view">
+<input type="hidden" name="token" value="%s" />
+    <table class="cart table table-bordered" cellspacing="1">
+    <thead><tr><th>Description</th><th class="textright">Price</th></tr></thead>%s
+    <tr class="subtotal"><td class="textright">Subtotal: &nbsp;</td><td class="textright">$%.2fUSD</td></tr>
+    <tr class="total"><td class="textright">Total Due Today: &nbsp;</td><td class="textright">$%.2fUSD</td></tr>
+    </table>
+</form>
+</section>
+<section class="customerDetails">
+<form method="post" action="/cart.php?a=checkout" id="mainfrm">
+<input type="hidden" name="token" value="%s" />
+<input type="hidden" value="" name="fpbb" id="fpbb" />
+<input type="hidden" value="" name="iobb" id="iobb" />
+<input type="hidden" name="submit" value="true" />
+<input type="hidden" name="custtype" id="custtype" value="existing" />
+<input type="hidden" name="allidprot" value="true" />
+<input type="hidden" name="paymentmethod" value="credit" />
+<input type="checkbox" name="accepttos" id="accepttos" value="on" required/><label for="accepttos">I have read and agree to the Terms &amp; Conditions</label>
+<a class="largeBtn primaryColor submit-checkout">Complete Order</a>
+</form>
+</section>`, captchaScript(captchaActionCheckout), alertsHTML(errMsg, ""), sess.token, rows.String(), total, total, sess.token))
+}
+
+// writeOrderCompletePage 输出订单完成页
+func (s *Server) writeOrderCompletePage(w http.ResponseWriter, sess *session) {
+	body := `<section class="completedOrder">
+	<div class="alert alert-danger">No order found</div>
+</section>`
+	if "" != sess.order {
+		body = fmt.Sprintf(`<section class="completedOrder">
+<p>Thank you for your order. You will receive a confirmation email shortly.</p>
+<div class="cartbox">
+<p align="center"><strong>Your Order Number is: %s</strong></p>
+</div>
+</section>`, sess.order)
+	}
+
+	s.writePage(w, sess, "Order Confirmation", body)
+}
@@ -0,0 +1,430 @@
+// Package freenomtest 提供一个进程内的 Freenom 模拟服务器，用于离线测试
+//
+// 服务器模拟了 clientarea.php、dologin.php、managedns 的增删改、
+// 域名管理页（domaindetails）中的管理工具、
+// domains.php?a=renewals/submitrenewals、fn-available.php
+// 以及购买域名使用的购物车（cart.php、fn-additional.php、cap_chk.php 等），
+// 账号、域名及 DNS 记录均保存在内存中
+package freenomtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 会话 cookie 名称，与 Freenom 站点一致
+const (
+	SessionCookie string = "WHMCSZH5eHTGhfvzP"
+	UserCookie    string = "WHMCSUser"
+)
+
+// 免费域名后缀
+var freeTLDs = []string{".tk", ".ml", ".ga", ".cf", ".gq"}
+
+// DefaultNameservers Freenom 默认域名服务器
+var DefaultNameservers = []string{"NS01.FREENOM.COM", "NS02.FREENOM.COM", "NS03.FREENOM.COM", "NS04.FREENOM.COM"}
+
+// Record DNS 记录
+type Record struct {
+	Type     string
+	Name     string
+	TTL      int
+	Value    string
+	Priority int // MX、SRV 记录的优先级
+	Weight   int // SRV 记录的权重
+	Port     int // SRV 记录的端口
+}
+
+// Domain 账号拥有的域名
+type Domain struct {
+	Name    string // 完整域名，例如 freenom-api.tk
+	ID      string // 域名 ID，为空时自动分配
+	RegDate time.Time
+	ExpDate time.Time
+	Status  string // 为空时为 Active
+	Type    string // 为空时为 Free
+	Records []Record
+
+	RenewError string // 不为空时提交续期总是失败并显示该信息，模拟 Freenom 拒绝续期
+
+	Forwarding  *Forwarding // URL 转发设置，为 nil 时使用 Freenom DNS
+	Nameservers []string    // 自定义域名服务器，为空时使用 Freenom 默认域名服务器
+	GlueRecords []GlueRecord
+}
+
+// GlueRecord 在注册局注册的子域名服务器
+type GlueRecord struct {
+	Host string // 完整主机名，例如 NS1.FREENOM-API.TK
+	IP   string
+}
+
+// Forwarding URL 转发设置
+type Forwarding struct {
+	URL             string
+	Type            int // 1 为 301 跳转，2 为框架隐藏
+	Title           string
+	MetaDescription string
+	MetaKeywords    string
+}
+
+// Account 账号
+type Account struct {
+	User     string
+	Password string
+	Name     string // 登录后页面上 Hello 后显示的名字
+	Domains  []*Domain
+}
+
+// session 浏览器会话
+type session struct {
+	id    string
+	token string
+	user  string // 已登录的账号，为空表示未登录
+
+	cart    []*cartItem // 购物车
+	captcha string      // 最近一次通过 cap_chk.php 校验的 reCAPTCHA 动作
+	order   string      // 最近一次结账的订单号
+}
+
+// Server 模拟 Freenom 站点的测试服务器
+type Server struct {
+	*httptest.Server
+
+	// Now 返回服务器当前时间，用于计算域名剩余天数，默认为 time.Now
+	Now func() time.Time
+
+	// DomainsPerPage 域名列表页每页显示的域名数，默认为 10，小于 1 时不分页
+	DomainsPerPage int
+
+	mu       sync.Mutex
+	accounts map[string]*Account
+	sessions map[string]*session
+	taken    map[string]bool
+	nextID   int64
+	logins   int
+}
+
+// NewServer 创建并启动模拟服务器，使用完毕后需要调用 Close
+func NewServer() *Server {
+	s := &Server{
+		Now:            time.Now,
+		DomainsPerPage: 10,
+		accounts:       make(map[string]*Account),
+		sessions:       make(map[string]*session),
+		taken:          make(map[string]bool),
+		nextID:         1000000000,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/clientarea.php", s.handleClientArea)
+	mux.HandleFunc("/dologin.php", s.handleDoLogin)
+	mux.HandleFunc("/domains.php", s.handleDomains)
+	mux.HandleFunc("/includes/domains/fn-available.php", s.handleAvailable)
+	mux.HandleFunc("/includes/domains/fn-additional.php", s.handleAddToCart)
+	mux.HandleFunc("/includes/domains/fn-remove.php", s.handleRemoveFromCart)
+	mux.HandleFunc("/includes/domains/confdomain-pricing.php", s.handleDomainPricing)
+	mux.HandleFunc("/includes/domains/confdomain-update.php", s.handleDomainUpdate)
+	mux.HandleFunc("/includes/domains/domainconfigure.php", s.handleDomainConfigure)
+	mux.HandleFunc("/includes/custom/cap_chk.php", s.handleCaptchaCheck)
+	mux.HandleFunc("/cart.php", s.handleCart)
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// AddAccount 添加账号
+func (s *Server) AddAccount(user, pwd string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := user
+	if i := strings.Index(user, "@"); i > 0 {
+		name = user[:i]
+	}
+
+	s.accounts[strings.ToLower(user)] = &Account{
+		User:     user,
+		Password: pwd,
+		Name:     name,
+	}
+}
+
+// AddDomain 给账号添加域名
+// 返回 域名 ID，账号不存在时返回空字符串
+func (s *Server) AddDomain(user string, d Domain) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[strings.ToLower(user)]
+	if !ok {
+		return ""
+	}
+
+	if "" == d.ID {
+		s.nextID++
+		d.ID = strconv.FormatInt(s.nextID, 10)
+	}
+
+	if "" == d.Status {
+		d.Status = "Active"
+	}
+
+	if "" == d.Type {
+		d.Type = "Free"
+	}
+
+	d.Records = append([]Record(nil), d.Records...)
+	d.Nameservers = append([]string(nil), d.Nameservers...)
+	d.GlueRecords = append([]GlueRecord(nil), d.GlueRecords...)
+	if nil != d.Forwarding {
+		fwd := *d.Forwarding
+		d.Forwarding = &fwd
+	}
+
+	a.Domains = append(a.Domains, &d)
+	return d.ID
+}
+
+// Domain 获取账号下指定域名的副本
+func (s *Server) Domain(user, name string) (d Domain, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, exists := s.accounts[strings.ToLower(user)]
+	if !exists {
+		return
+	}
+
+	for _, v := range a.Domains {
+		if strings.EqualFold(v.Name, name) {
+			d = *v
+			d.Records = append([]Record(nil), v.Records...)
+			d.Nameservers = append([]string(nil), v.Nameservers...)
+			d.GlueRecords = append([]GlueRecord(nil), v.GlueRecords...)
+			if nil != v.Forwarding {
+				fwd := *v.Forwarding
+				d.Forwarding = &fwd
+			}
+
+			return d, true
+		}
+	}
+
+	return
+}
+
+// Take 标记域名已被他人注册，fn-available.php 将返回不可用
+func (s *Server) Take(domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.taken[strings.ToLower(domain)] = true
+}
+
+// Release 取消 Take 的标记，模拟他人注册的域名过期后重新开放注册
+func (s *Server) Release(domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.taken, strings.ToLower(domain))
+}
+
+// ExpireSessions 使所有会话过期，模拟 WHMCS 会话 cookie 失效
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions = make(map[string]*session)
+}
+
+// Logins 返回成功登录的次数
+func (s *Server) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logins
+}
+
+// randomHex 生成随机十六进制字符串
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// getSession 获取请求对应的会话，不存在时创建新会话
+// 调用方需持有 s.mu
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) *session {
+	if ck, err := r.Cookie(SessionCookie); nil == err {
+		if sess, ok := s.sessions[ck.Value]; ok {
+			return sess
+		}
+	}
+
+	sess := &session{
+		id:    randomHex(13),
+		token: randomHex(20),
+	}
+	s.sessions[sess.id] = sess
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    sess.id,
+		Path:     "/",
+		HttpOnly: true,
+	})
+
+	return sess
+}
+
+// lookupSession 获取请求对应的会话，不存在时返回 nil，不创建新会话
+// 调用方需持有 s.mu
+func (s *Server) lookupSession(r *http.Request) *session {
+	if ck, err := r.Cookie(SessionCookie); nil == err {
+		return s.sessions[ck.Value]
+	}
+
+	return nil
+}
+
+// account 获取会话已登录的账号
+// 调用方需持有 s.mu
+func (s *Server) account(sess *session) *Account {
+	if "" == sess.user {
+		return nil
+	}
+
+	return s.accounts[sess.user]
+}
+
+// findDomain 查找账号下的域名
+func (a *Account) findDomain(name, id string) *Domain {
+	for _, d := range a.Domains {
+		if ("" == name || strings.EqualFold(d.Name, name)) && ("" == id || d.ID == id) {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// daysLeft 域名剩余天数
+func (s *Server) daysLeft(d *Domain) int {
+	return int(d.ExpDate.Sub(s.Now()).Hours() / 24)
+}
+
+func (s *Server) handleDoLogin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	r.ParseForm()
+
+	a, ok := s.accounts[strings.ToLower(r.PostForm.Get("username"))]
+	if "POST" != r.Method || r.PostForm.Get("token") != sess.token ||
+		!ok || a.Password != r.PostForm.Get("password") {
+		http.Redirect(w, r, "clientarea.php?incorrect=true", http.StatusFound)
+		return
+	}
+
+	sess.user = strings.ToLower(a.User)
+	s.logins++
+	http.SetCookie(w, &http.Cookie{
+		Name:     UserCookie,
+		Value:    randomHex(20),
+		Path:     "/",
+		HttpOnly: true,
+	})
+
+	http.Redirect(w, r, "clientarea.php", http.StatusFound)
+}
+
+func (s *Server) handleClientArea(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	a := s.account(sess)
+	if nil == a {
+		s.writeLoginPage(w, sess)
+		return
+	}
+
+	q := r.URL.Query()
+	switch {
+	case "" != q.Get("managedns"):
+		s.handleManageDNS(w, r, sess, a)
+
+	case "domaindetails" == q.Get("action"):
+		s.handleDomainDetails(w, r, sess, a)
+
+	case "domains" == q.Get("action"):
+		page, _ := strconv.Atoi(q.Get("page"))
+		s.writeDomainsPage(w, sess, a, page)
+
+	default:
+		s.writeHomePage(w, sess)
+	}
+}
+
+func (s *Server) handleDomains(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.getSession(w, r)
+	a := s.account(sess)
+	if nil == a {
+		s.writeLoginPage(w, sess)
+		return
+	}
+
+	q := r.URL.Query()
+	switch {
+	case "true" == q.Get("submitrenewals"):
+		s.handleSubmitRenewals(w, r, sess, a)
+
+	case "renewals" == q.Get("a"):
+		s.writeRenewalsPage(w, sess, a)
+
+	default:
+		s.writeDomainsPage(w, sess, a, 1)
+	}
+}
+
+func (s *Server) handleSubmitRenewals(w http.ResponseWriter, r *http.Request, sess *session, a *Account) {
+	r.ParseForm()
+
+	d := a.findDomain("", r.PostForm.Get("renewalid"))
+	if "POST" != r.Method || r.PostForm.Get("token") != sess.token || nil == d {
+		s.writeRenewFailedPage(w, sess, "Invalid renewal request")
+		return
+	}
+
+	period := r.PostForm.Get("renewalperiod[" + d.ID + "]")
+	months, err := strconv.Atoi(strings.TrimSuffix(period, "M"))
+	if nil != err || months < 1 || months > 12 {
+		s.writeRenewFailedPage(w, sess, "Invalid renewal period")
+		return
+	}
+
+	if s.daysLeft(d) > 14 {
+		s.writeRenewFailedPage(w, sess, "This domain is not yet eligible for renewal")
+		return
+	}
+
+	if "" != d.RenewError {
+		s.writeRenewFailedPage(w, sess, d.RenewError)
+		return
+	}
+
+	d.ExpDate = d.ExpDate.AddDate(0, months, 0)
+
+	s.nextID++
+	s.writeRenewSuccessPage(w, sess, strconv.FormatInt(s.nextID, 10))
+}
@@ -0,0 +1,63 @@
+package freenomtest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, hc *http.Client, rawURL string) string {
+	res, err := hc.Get(rawURL)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer res.Body.Close()
+
+	all, _ := ioutil.ReadAll(res.Body)
+	return string(all)
+}
+
+func TestServerLogin(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.AddAccount("user@example.com", "secret")
+	id := srv.AddDomain("user@example.com", Domain{
+		Name:    "example.tk",
+		RegDate: time.Now(),
+		ExpDate: time.Now().AddDate(1, 0, 0),
+	})
+	if "" == id {
+		t.Fatal("AddDomain failed")
+	}
+
+	jar, _ := cookiejar.New(nil)
+	hc := &http.Client{Jar: jar}
+
+	// 未登录时访问域名列表返回登录页
+	page := get(t, hc, srv.URL+"/clientarea.php?action=domains")
+	m := regexp.MustCompile(`name="token" value="([^"]+)"`).FindStringSubmatch(page)
+	if 2 != len(m) || strings.Contains(page, "example.tk") {
+		t.Fatalf("expect login page, got: %s", page)
+	}
+
+	res, err := hc.PostForm(srv.URL+"/dologin.php", url.Values{
+		"token":    {m[1]},
+		"username": {"user@example.com"},
+		"password": {"secret"},
+	})
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	res.Body.Close()
+
+	page = get(t, hc, srv.URL+"/clientarea.php?action=domains")
+	if !strings.Contains(page, `<span class="hidden-sm">Hello user`) || !strings.Contains(page, "id="+id) {
+		t.Errorf("expect domain list, got: %s", page)
+	}
+}
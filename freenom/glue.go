@@ -0,0 +1,247 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+// GlueRecord 在注册局注册的子域名服务器（glue 记录），例如 ns1.example.tk 及其 IP
+type GlueRecord struct {
+	Host string // 完整主机名
+	IP   string // IPv4 或 IPv6 地址
+}
+
+// registerNSPath 注册域名服务器页的地址
+func registerNSPath(domainID string) (path string, query url.Values) {
+	query = url.Values{}
+	query.Add("action", "domaindetails")
+	query.Add("id", domainID)
+	query.Add("modop", "custom")
+	query.Add("a", "registerns")
+	return loginPath, query
+}
+
+// glueHostPrefix 获取子域名服务器在域名下的前缀
+// 参数 host 可以是前缀（ns1），也可以是完整主机名（ns1.example.tk），
+// 不以该域名结尾的主机名均视为前缀；域名本身没有前缀，不能注册为子域名服务器
+func glueHostPrefix(op, domain, host string) (prefix, fullHost string, err error) {
+	prefix = strings.TrimSuffix(strings.TrimSpace(host), ".")
+	if strings.EqualFold(domain, prefix) {
+		prefix = ""
+	} else if suffix := "." + strings.ToLower(domain); strings.HasSuffix(strings.ToLower(prefix), suffix) {
+		prefix = prefix[:len(prefix)-len(suffix)]
+	}
+
+	if "" == prefix || !validHostname(prefix) {
+		return "", "", fmt.Errorf("%s %q: %w", op, host, ErrInvalidNameservers)
+	}
+
+	return prefix, prefix + "." + domain, nil
+}
+
+// validateIP 校验 IPv4 或 IPv6 地址
+func validateIP(op, ip string) error {
+	if nil == net.ParseIP(ip) {
+		return fmt.Errorf("%s %q: %w", op, ip, ErrInvalidIP)
+	}
+
+	return nil
+}
+
+// ListGlueRecords 列出域名下已注册的子域名服务器
+// 等同于使用 context.Background() 调用 ListGlueRecordsContext
+func (c *Client) ListGlueRecords(domain string) (records []*GlueRecord, err error) {
+	return c.ListGlueRecordsContext(context.Background(), domain)
+}
+
+// ListGlueRecordsContext 列出域名下已注册的子域名服务器
+// ctx 被取消时中止请求及重试
+func (c *Client) ListGlueRecordsContext(ctx context.Context, domain string) (records []*GlueRecord, err error) {
+	if jar, _ := c.session(); nil == jar {
+		return nil, ErrNotLoggedIn
+	}
+
+	var info *DomainInfo
+	info, err = c.lookupDomain(ctx, domain)
+	if nil != err {
+		return
+	}
+
+	path, query := registerNSPath(info.DomainID)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    "ListGlueRecords",
+		path:    path,
+		query:   query,
+		referer: loginPath + "?action=domaindetails&id=" + info.DomainID,
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.GlueRecordsPage
+	page, err = scrape.ParseGlueRecords(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("ListGlueRecords %w", err)
+		return
+	}
+
+	records = make([]*GlueRecord, 0, len(page.Records))
+	for _, row := range page.Records {
+		records = append(records, &GlueRecord{
+			Host: row.Host,
+			IP:   row.IP,
+		})
+	}
+
+	return
+}
+
+// CreateGlueRecord 在注册局注册子域名服务器
+// 等同于使用 context.Background() 调用 CreateGlueRecordContext
+func (c *Client) CreateGlueRecord(domain, host, ip string) (err error) {
+	return c.CreateGlueRecordContext(context.Background(), domain, host, ip)
+}
+
+// CreateGlueRecordContext 在注册局注册子域名服务器
+// 参数 host 可以是前缀（ns1）或完整主机名（ns1.example.tk）
+// 参数 ip 子域名服务器的 IPv4 或 IPv6 地址，不合法时返回 ErrInvalidIP
+// 注册局拒绝时返回 *GlueError
+// ctx 被取消时中止请求及重试
+func (c *Client) CreateGlueRecordContext(ctx context.Context, domain, host, ip string) (err error) {
+	const op = "CreateGlueRecord"
+
+	var prefix, fullHost string
+	prefix, fullHost, err = glueHostPrefix(op, domain, host)
+	if nil != err {
+		return
+	}
+
+	if err = validateIP(op, ip); nil != err {
+		return
+	}
+
+	params := url.Values{}
+	params.Add("sub", "save")
+	params.Add("ns", prefix)
+	params.Add("ipaddress", ip)
+	return c.submitGlue(ctx, op, domain, fullHost, params)
+}
+
+// UpdateGlueRecord 修改子域名服务器的 IP
+// 等同于使用 context.Background() 调用 UpdateGlueRecordContext
+func (c *Client) UpdateGlueRecord(domain, host, oldIP, newIP string) (err error) {
+	return c.UpdateGlueRecordContext(context.Background(), domain, host, oldIP, newIP)
+}
+
+// UpdateGlueRecordContext 修改子域名服务器的 IP
+// 参数 oldIP 注册局中当前的 IP，newIP 新的 IP，不合法时返回 ErrInvalidIP
+// 注册局拒绝时返回 *GlueError
+// ctx 被取消时中止请求及重试
+func (c *Client) UpdateGlueRecordContext(ctx context.Context, domain, host, oldIP, newIP string) (err error) {
+	const op = "UpdateGlueRecord"
+
+	var prefix, fullHost string
+	prefix, fullHost, err = glueHostPrefix(op, domain, host)
+	if nil != err {
+		return
+	}
+
+	if err = validateIP(op, oldIP); nil != err {
+		return
+	}
+
+	if err = validateIP(op, newIP); nil != err {
+		return
+	}
+
+	params := url.Values{}
+	params.Add("sub", "modify")
+	params.Add("ns", prefix)
+	params.Add("currentipaddress", oldIP)
+	params.Add("newipaddress", newIP)
+	return c.submitGlue(ctx, op, domain, fullHost, params)
+}
+
+// DeleteGlueRecord 删除子域名服务器
+// 等同于使用 context.Background() 调用 DeleteGlueRecordContext
+func (c *Client) DeleteGlueRecord(domain, host string) (err error) {
+	return c.DeleteGlueRecordContext(context.Background(), domain, host)
+}
+
+// DeleteGlueRecordContext 删除子域名服务器
+// 注册局拒绝时返回 *GlueError
+// ctx 被取消时中止请求及重试
+func (c *Client) DeleteGlueRecordContext(ctx context.Context, domain, host string) (err error) {
+	const op = "DeleteGlueRecord"
+
+	var prefix, fullHost string
+	prefix, fullHost, err = glueHostPrefix(op, domain, host)
+	if nil != err {
+		return
+	}
+
+	params := url.Values{}
+	params.Add("sub", "delete")
+	params.Add("ns", prefix)
+	return c.submitGlue(ctx, op, domain, fullHost, params)
+}
+
+// submitGlue 提交子域名服务器操作
+func (c *Client) submitGlue(ctx context.Context, op, domain, host string, params url.Values) (err error) {
+	jar, token := c.session()
+	if nil == jar {
+		return ErrNotLoggedIn
+	}
+
+	var info *DomainInfo
+	info, err = c.lookupDomain(ctx, domain)
+	if nil != err {
+		return
+	}
+
+	params.Set("token", token)
+	path, query := registerNSPath(info.DomainID)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    op,
+		method:  "POST",
+		path:    path,
+		query:   query,
+		form:    params,
+		referer: path + "?" + query.Encode(),
+		noRetry: true, // 注册局操作不是幂等的，重试可能导致重复注册
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.GlueRecordsPage
+	page, err = scrape.ParseGlueRecords(bytes.NewReader(all))
+	if nil != err {
+		return fmt.Errorf("%s %w", op, err)
+	}
+
+	if 0 != len(page.Errors) {
+		return &GlueError{
+			Op:      op,
+			Domain:  domain,
+			Host:    host,
+			Message: strings.Join(page.Errors, "; "),
+		}
+	}
+
+	if 0 == len(page.Success) {
+		return fmt.Errorf("%s not success: %w", op, ErrUnexpectedResponse)
+	}
+
+	return
+}
@@ -0,0 +1,79 @@
+package freenom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGlueRecords(t *testing.T) {
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	c := DefaultClient()
+	if err := c.CreateGlueRecord(freenomDomain, "ns1", "203.0.113.10"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err := c.CreateGlueRecord(freenomDomain, "NS2."+freenomDomain+".", "2001:db8::53"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	records, err := c.ListGlueRecords(freenomDomain)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 2 != len(records) || !strings.EqualFold("ns2."+freenomDomain, records[1].Host) || "2001:db8::53" != records[1].IP {
+		t.Fatalf("unexpected glue records: %+v", records)
+	}
+
+	if err = c.UpdateGlueRecord(freenomDomain, "ns1."+freenomDomain, "203.0.113.10", "198.51.100.1"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.DeleteGlueRecord(freenomDomain, "ns2"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	d, _ := srv.Domain(freenomUser, freenomDomain)
+	if 1 != len(d.GlueRecords) || "198.51.100.1" != d.GlueRecords[0].IP {
+		t.Errorf("unexpected glue records on server: %+v", d.GlueRecords)
+	}
+
+	// 客户端校验
+	if err = c.CreateGlueRecord(freenomDomain, "ns3", "300.1.1.1"); !errors.Is(err, ErrInvalidIP) {
+		t.Errorf("expect ErrInvalidIP, got %v", err)
+	}
+
+	if err = c.UpdateGlueRecord(freenomDomain, "ns1", "198.51.100.1", "not-an-ip"); !errors.Is(err, ErrInvalidIP) {
+		t.Errorf("expect ErrInvalidIP, got %v", err)
+	}
+
+	for _, bad := range []string{"bad host", freenomDomain, "." + freenomDomain, strings.ToUpper(freenomDomain) + "."} {
+		if err = c.DeleteGlueRecord(freenomDomain, bad); !errors.Is(err, ErrInvalidNameservers) {
+			t.Errorf("expect ErrInvalidNameservers for %q, got %v", bad, err)
+		}
+	}
+
+	// 注册局拒绝
+	var ge *GlueError
+	if err = c.CreateGlueRecord(freenomDomain, "ns3", "10.0.0.1"); !errors.As(err, &ge) {
+		t.Fatalf("expect GlueError, got %v", err)
+	}
+
+	if !strings.EqualFold("ns3."+freenomDomain, ge.Host) || !strings.Contains(ge.Message, "not a public address") {
+		t.Errorf("unexpected GlueError: %+v", ge)
+	}
+
+	if err = c.CreateGlueRecord(freenomDomain, "ns1", "198.51.100.2"); !errors.As(err, &ge) {
+		t.Errorf("expect GlueError for duplicated host, got %v", err)
+	}
+
+	if err = c.DeleteGlueRecord(freenomDomain, "ns9"); !errors.As(err, &ge) {
+		t.Errorf("expect GlueError for unknown host, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package scrape
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ConfDomain 域名配置页中的一个域名
+type ConfDomain struct {
+	Domain string `json:"domain"`
+	Period string `json:"period"` // 页面初始显示的注册时长，例如 3M、12M
+}
+
+// ConfDomainsPage 购物车中的域名配置页 cart.php?a=confdomains
+type ConfDomainsPage struct {
+	LoggedIn       bool          `json:"logged_in"`
+	Errors         []string      `json:"errors"`
+	CaptchaSiteKey string        `json:"captcha_site_key"` // 页面加载的 reCAPTCHA v3 的站点密钥
+	Domains        []*ConfDomain `json:"domains"`
+	Form           url.Values    `json:"form"` // 配置表单中的隐藏字段，包括令牌、ID Shield 及默认域名服务器
+}
+
+// CartRow 购物车中的一项
+type CartRow struct {
+	Description  string `json:"description"`   // 例如 Domain Registration - freenom-api.tk
+	Domain       string `json:"domain"`        // 描述中的域名，不是域名注册时为空
+	Price        string `json:"price"`         // 页面上显示的价格，例如 $0.00USD
+	RegularPrice string `json:"regular_price"` // 划掉的原价，没有优惠时为空
+}
+
+// CartPage 购物车页 cart.php?a=view
+type CartPage struct {
+	LoggedIn       bool       `json:"logged_in"`
+	Errors         []string   `json:"errors"`
+	CaptchaSiteKey string     `json:"captcha_site_key"`
+	Items          []*CartRow `json:"items"`
+	Subtotal       string     `json:"subtotal"`
+	Total          string     `json:"total"`    // Total Due Today
+	Checkout       url.Values `json:"checkout"` // id="mainfrm" 的结账表单中的字段，不含未勾选的服务条款
+}
+
+// ParseConfDomains 解析域名配置页
+// 域名取自 class="tableDomain" 的单元格，注册时长取自同一行中带有 initial 属性的元素
+func ParseConfDomains(r io.Reader) (page *ConfDomainsPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &ConfDomainsPage{
+		LoggedIn:       loggedIn(doc),
+		CaptchaSiteKey: captchaSiteKey(doc),
+	}
+	page.Errors, _ = alerts(doc)
+
+	form := find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Form) && hasAttr(n, "data-domain-configuration-form")
+	})
+	if nil == form {
+		return
+	}
+
+	page.Form = formValues(form)
+
+	for _, row := range findAll(form, func(n *html.Node) bool {
+		return isElement(n, atom.Tr)
+	}) {
+		cell := find(row, func(n *html.Node) bool {
+			return html.ElementNode == n.Type && hasClass(n, "tableDomain")
+		})
+		if nil == cell {
+			continue
+		}
+
+		d := &ConfDomain{
+			Domain: strings.ToLower(text(cell)),
+		}
+
+		if period := find(row, func(n *html.Node) bool {
+			return html.ElementNode == n.Type && hasAttr(n, "initial")
+		}); nil != period {
+			d.Period = attr(period, "initial")
+		}
+
+		page.Domains = append(page.Domains, d)
+	}
+
+	return
+}
+
+// ParseCart 解析购物车页
+func ParseCart(r io.Reader) (page *CartPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &CartPage{
+		LoggedIn:       loggedIn(doc),
+		CaptchaSiteKey: captchaSiteKey(doc),
+	}
+	page.Errors, _ = alerts(doc)
+
+	for _, row := range findAll(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Tr)
+	}) {
+		switch {
+		case hasClass(row, "carttableproduct"):
+			page.Items = append(page.Items, cartRow(row))
+
+		case hasClass(row, "subtotal"):
+			page.Subtotal = lastCell(row)
+
+		case hasClass(row, "total"):
+			page.Total = lastCell(row)
+		}
+	}
+
+	if form := find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Form) && "mainfrm" == attr(n, "id")
+	}); nil != form {
+		page.Checkout = formValues(form)
+	}
+
+	return
+}
+
+// cartRow 解析购物车中的一项
+// 价格单元格中 <strike> 内为原价，其余为实际价格
+func cartRow(row *html.Node) *CartRow {
+	item := &CartRow{}
+
+	if n := find(row, func(n *html.Node) bool {
+		return isElement(n, atom.Td) && hasClass(n, "tableproduct")
+	}); nil != n {
+		item.Description = text(n)
+		if i := strings.LastIndex(item.Description, " - "); i >= 0 {
+			item.Domain = strings.ToLower(item.Description[i+3:])
+		}
+	}
+
+	if n := find(row, func(n *html.Node) bool {
+		return isElement(n, atom.Td) && hasClass(n, "pricing")
+	}); nil != n {
+		item.Price = text(n)
+		if strike := find(n, func(n *html.Node) bool {
+			return isElement(n, atom.Strike)
+		}); nil != strike {
+			item.RegularPrice = text(strike)
+			item.Price = strings.TrimSpace(strings.Replace(item.Price, item.RegularPrice, "", 1))
+		}
+	}
+
+	return item
+}
+
+// lastCell 获取行中最后一个单元格的文本
+func lastCell(row *html.Node) string {
+	cells := findAll(row, func(n *html.Node) bool {
+		return isElement(n, atom.Td)
+	})
+	if 0 == len(cells) {
+		return ""
+	}
+
+	return text(cells[len(cells)-1])
+}
+
+// captchaSiteKey 获取页面加载 reCAPTCHA v3 时使用的站点密钥
+// 即 <script src="https://www.google.com/recaptcha/api.js?render=KEY"> 中的 render 参数
+func captchaSiteKey(doc *html.Node) string {
+	script := find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Script) && strings.Contains(attr(n, "src"), "/recaptcha/api.js")
+	})
+	if nil == script {
+		return ""
+	}
+
+	return queryParam(attr(script, "src"), "render")
+}
@@ -0,0 +1,25 @@
+package scrape
+
+import "testing"
+
+func TestParseConfDomains(t *testing.T) {
+	for _, name := range []string{"confdomains"} {
+		page, err := ParseConfDomains(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
+
+func TestParseCart(t *testing.T) {
+	for _, name := range []string{"cart", "cart_error"} {
+		page, err := ParseCart(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
@@ -0,0 +1,49 @@
+package scrape
+
+import (
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ClientAreaPage clientarea.php 页面（未登录时为登录页，已登录时为用户中心首页）
+type ClientAreaPage struct {
+	LoggedIn bool   `json:"logged_in"` // 页头是否带有 Hello 标记
+	Token    string `json:"token"`     // 页面表单中的令牌
+}
+
+// ParseClientArea 解析 clientarea.php 页面
+// 令牌优先取自登录表单（class="form-stacked"），否则取页面中第一个 token 输入框
+func ParseClientArea(r io.Reader) (page *ClientAreaPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &ClientAreaPage{
+		LoggedIn: loggedIn(doc),
+	}
+
+	isToken := func(n *html.Node) bool {
+		return isElement(n, atom.Input) && "token" == attr(n, "name")
+	}
+
+	var input *html.Node
+	if form := find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Form) && hasClass(n, "form-stacked")
+	}); nil != form {
+		input = find(form, isToken)
+	}
+
+	if nil == input {
+		input = find(doc, isToken)
+	}
+
+	if nil != input {
+		page.Token = attr(input, "value")
+	}
+
+	return
+}
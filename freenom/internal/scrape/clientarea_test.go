@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseClientArea(t *testing.T) {
+	for _, name := range []string{"clientarea_login", "clientarea_home"} {
+		page, err := ParseClientArea(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
@@ -0,0 +1,110 @@
+package scrape
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 记录表单输入框的名称，例如 records[0][value]
+var reRecordInput = regexp.MustCompile(`^records\[(\d+)\]\[(\w+)\]$`)
+
+// DNSRecord DNS 管理页中的一条记录
+type DNSRecord struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	TTL      int    `json:"ttl"`
+	Value    string `json:"value"`
+	Priority int    `json:"priority"`
+	Weight   int    `json:"weight"`
+	Port     int    `json:"port"`
+}
+
+// DNSPage clientarea.php?managedns= DNS 记录管理页
+type DNSPage struct {
+	LoggedIn bool         `json:"logged_in"`
+	Errors   []string     `json:"errors"`  // class="dnserror" 的错误信息
+	Success  []string     `json:"success"` // class="dnssuccess" 的成功信息
+	Records  []*DNSRecord `json:"records"`
+}
+
+// ParseDNS 解析 DNS 记录管理页
+// 记录取自名称为 records[N][字段] 的输入框，按 N 首次出现的顺序排列
+func ParseDNS(r io.Reader) (page *DNSPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &DNSPage{
+		LoggedIn: loggedIn(doc),
+	}
+
+	for _, li := range findAll(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Li) && (hasClass(n, "dnserror") || hasClass(n, "dnssuccess"))
+	}) {
+		if hasClass(li, "dnserror") {
+			page.Errors = append(page.Errors, text(li))
+		} else {
+			page.Success = append(page.Success, text(li))
+		}
+	}
+
+	byIndex := make(map[string]*DNSRecord)
+	for _, input := range findAll(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Input) && reRecordInput.MatchString(attr(n, "name"))
+	}) {
+		match := reRecordInput.FindStringSubmatch(attr(input, "name"))
+		rec, ok := byIndex[match[1]]
+		if !ok {
+			rec = &DNSRecord{}
+			byIndex[match[1]] = rec
+			page.Records = append(page.Records, rec)
+		}
+
+		value := attr(input, "value")
+		switch match[2] {
+		case "type":
+			rec.Type = value
+
+		case "name":
+			rec.Name = value
+
+		case "value":
+			rec.Value = value
+
+		case "ttl":
+			if rec.TTL, err = strconv.Atoi(value); nil != err {
+				err = fmt.Errorf("ParseDNS Atoi ttl %s err: %w", value, err)
+				return
+			}
+
+		case "priority", "weight", "port":
+			if "" == value {
+				continue
+			}
+
+			var n int
+			if n, err = strconv.Atoi(value); nil != err {
+				err = fmt.Errorf("ParseDNS Atoi %s %s err: %w", match[2], value, err)
+				return
+			}
+
+			switch match[2] {
+			case "priority":
+				rec.Priority = n
+			case "weight":
+				rec.Weight = n
+			default:
+				rec.Port = n
+			}
+		}
+	}
+
+	return
+}
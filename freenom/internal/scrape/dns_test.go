@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseDNS(t *testing.T) {
+	for _, name := range []string{"dns"} {
+		page, err := ParseDNS(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
@@ -0,0 +1,119 @@
+package scrape
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DomainRow 域名列表中的一行
+type DomainRow struct {
+	Domain  string `json:"domain"`
+	RegDate string `json:"reg_date"` // 注册日期，格式为 2006-01-02
+	ExpDate string `json:"exp_date"` // 到期日期，格式为 2006-01-02
+	Status  string `json:"status"`
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+}
+
+// DomainsPage clientarea.php?action=domains 域名列表页
+type DomainsPage struct {
+	LoggedIn bool         `json:"logged_in"`
+	Domains  []*DomainRow `json:"domains"`
+	NextPage string       `json:"next_page"` // 下一页的链接，已是最后一页时为空
+}
+
+// ParseDomains 解析域名列表页
+// 每行的各列通过 class 为 second 至 seventh 的单元格区分
+func ParseDomains(r io.Reader) (page *DomainsPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &DomainsPage{
+		LoggedIn: loggedIn(doc),
+	}
+
+	rows := findAll(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Tr)
+	})
+
+	for _, tr := range rows {
+		cells := make(map[string]*html.Node)
+		for _, td := range children(tr, atom.Td) {
+			for _, class := range []string{"second", "third", "fourth", "fifth", "sixth", "seventh"} {
+				if hasClass(td, class) {
+					cells[class] = td
+				}
+			}
+		}
+
+		if nil == cells["second"] || nil == cells["seventh"] {
+			continue
+		}
+
+		row := &DomainRow{
+			Domain: text(cells["second"]),
+		}
+
+		if td := cells["third"]; nil != td {
+			row.RegDate = text(td)
+		}
+
+		if td := cells["fourth"]; nil != td {
+			row.ExpDate = text(td)
+		}
+
+		if td := cells["fifth"]; nil != td {
+			row.Status = text(td)
+		}
+
+		if td := cells["sixth"]; nil != td {
+			row.Type = text(td)
+		}
+
+		if a := find(cells["seventh"], func(n *html.Node) bool {
+			return isElement(n, atom.A) && "" != queryParam(attr(n, "href"), "id")
+		}); nil != a {
+			row.ID = queryParam(attr(a, "href"), "id")
+		}
+
+		page.Domains = append(page.Domains, row)
+	}
+
+	page.NextPage = nextPage(doc)
+	return
+}
+
+// nextPage 查找翻页链接中的下一页
+// 下一页链接位于 class="next" 的 li 中，最后一页时该 li 带有 disabled 且链接为 #
+func nextPage(doc *html.Node) string {
+	a := find(doc, func(n *html.Node) bool {
+		if isElement(n, atom.A) && "next" == attr(n, "rel") {
+			return true
+		}
+
+		return isElement(n, atom.Li) && hasClass(n, "next") && !hasClass(n, "disabled")
+	})
+	if nil == a {
+		return ""
+	}
+
+	if !isElement(a, atom.A) {
+		if a = find(a, func(n *html.Node) bool {
+			return isElement(n, atom.A)
+		}); nil == a {
+			return ""
+		}
+	}
+
+	if href := attr(a, "href"); "#" != href && !strings.HasPrefix(href, "javascript:") {
+		return href
+	}
+
+	return ""
+}
@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseDomains(t *testing.T) {
+	for _, name := range []string{"domains", "domains_last"} {
+		page, err := ParseDomains(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
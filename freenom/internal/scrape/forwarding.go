@@ -0,0 +1,56 @@
+package scrape
+
+import (
+	"io"
+	"strconv"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// URLForwardingPage 域名管理中的 URL 转发页
+// clientarea.php?action=domaindetails&id=ID&modop=custom&a=urlforwarding
+type URLForwardingPage struct {
+	LoggedIn        bool     `json:"logged_in"`
+	Errors          []string `json:"errors"`
+	Success         []string `json:"success"`
+	Token           string   `json:"token"`
+	Mode            string   `json:"mode"` // dns 表示使用 Freenom DNS，forward 表示使用 URL 转发
+	URL             string   `json:"url"`
+	ForwardType     int      `json:"forward_type"`
+	Title           string   `json:"title"`
+	MetaDescription string   `json:"meta_description"`
+	MetaKeywords    string   `json:"meta_keywords"`
+}
+
+// ParseURLForwarding 解析 URL 转发页
+// 各项取自 class="urlForwarding" 的表单中当前选中或填写的值
+func ParseURLForwarding(r io.Reader) (page *URLForwardingPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &URLForwardingPage{
+		LoggedIn: loggedIn(doc),
+	}
+	page.Errors, page.Success = alerts(doc)
+
+	form := find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Form) && hasClass(n, "urlForwarding")
+	})
+	if nil == form {
+		return
+	}
+
+	values := formValues(form)
+	page.Token = values.Get("token")
+	page.Mode = values.Get("mode")
+	page.URL = values.Get("url")
+	page.Title = values.Get("title")
+	page.MetaDescription = values.Get("meta_description")
+	page.MetaKeywords = values.Get("meta_keywords")
+	page.ForwardType, _ = strconv.Atoi(values.Get("forward_type"))
+	return
+}
@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseURLForwarding(t *testing.T) {
+	for _, name := range []string{"forwarding"} {
+		page, err := ParseURLForwarding(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
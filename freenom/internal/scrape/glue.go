@@ -0,0 +1,76 @@
+package scrape
+
+import (
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// GlueRow 已注册的子域名服务器（glue 记录）
+type GlueRow struct {
+	Host string `json:"host"`
+	IP   string `json:"ip"`
+}
+
+// GlueRecordsPage 域名管理中的注册域名服务器页
+// clientarea.php?action=domaindetails&id=ID&modop=custom&a=registerns
+type GlueRecordsPage struct {
+	LoggedIn bool       `json:"logged_in"`
+	Errors   []string   `json:"errors"`
+	Success  []string   `json:"success"`
+	Token    string     `json:"token"`
+	Records  []*GlueRow `json:"records"`
+}
+
+// ParseGlueRecords 解析注册域名服务器页
+// 已注册的记录取自 class="glueRecords" 的表格中 class 为 host、ip 的单元格
+func ParseGlueRecords(r io.Reader) (page *GlueRecordsPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &GlueRecordsPage{
+		LoggedIn: loggedIn(doc),
+	}
+	page.Errors, page.Success = alerts(doc)
+
+	if form := find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Form) && hasClass(n, "registerNameserver")
+	}); nil != form {
+		page.Token = formValues(form).Get("token")
+	}
+
+	table := find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Table) && hasClass(n, "glueRecords")
+	})
+	if nil == table {
+		return
+	}
+
+	for _, tr := range findAll(table, func(n *html.Node) bool {
+		return isElement(n, atom.Tr)
+	}) {
+		var host, ip *html.Node
+		for _, td := range children(tr, atom.Td) {
+			if hasClass(td, "host") {
+				host = td
+			} else if hasClass(td, "ip") {
+				ip = td
+			}
+		}
+
+		if nil == host || nil == ip {
+			continue
+		}
+
+		page.Records = append(page.Records, &GlueRow{
+			Host: text(host),
+			IP:   text(ip),
+		})
+	}
+
+	return
+}
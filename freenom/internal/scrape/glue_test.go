@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseGlueRecords(t *testing.T) {
+	for _, name := range []string{"glue"} {
+		page, err := ParseGlueRecords(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
@@ -0,0 +1,56 @@
+package scrape
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 域名服务器页最多可填写的域名服务器数
+const maxNameservers int = 5
+
+// NameserversPage 域名管理中的域名服务器页
+// clientarea.php?action=domaindetails&id=ID&modop=custom&a=nameservers
+type NameserversPage struct {
+	LoggedIn    bool     `json:"logged_in"`
+	Errors      []string `json:"errors"`
+	Success     []string `json:"success"`
+	Token       string   `json:"token"`
+	Choice      string   `json:"choice"`      // default 表示使用 Freenom 默认域名服务器，custom 表示使用自定义域名服务器
+	Nameservers []string `json:"nameservers"` // ns1 至 ns5 中已填写的域名服务器
+}
+
+// ParseNameservers 解析域名服务器页
+// 各项取自 class="nameservers" 的表单中当前选中或填写的值
+func ParseNameservers(r io.Reader) (page *NameserversPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &NameserversPage{
+		LoggedIn: loggedIn(doc),
+	}
+	page.Errors, page.Success = alerts(doc)
+
+	form := find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Form) && hasClass(n, "nameservers")
+	})
+	if nil == form {
+		return
+	}
+
+	values := formValues(form)
+	page.Token = values.Get("token")
+	page.Choice = values.Get("nschoice")
+	for i := 1; i <= maxNameservers; i++ {
+		if ns := values.Get(fmt.Sprintf("ns%d", i)); "" != ns {
+			page.Nameservers = append(page.Nameservers, ns)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseNameservers(t *testing.T) {
+	for _, name := range []string{"nameservers_custom", "nameservers_default"} {
+		page, err := ParseNameservers(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
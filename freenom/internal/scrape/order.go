@@ -0,0 +1,34 @@
+package scrape
+
+import (
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// OrderCompletePage 结账后的订单完成页 cart.php?a=complete
+type OrderCompletePage struct {
+	LoggedIn    bool     `json:"logged_in"`
+	Errors      []string `json:"errors"`
+	OrderNumber string   `json:"order_number"` // 下单成功时的订单号
+}
+
+// ParseOrderComplete 解析订单完成页
+func ParseOrderComplete(r io.Reader) (page *OrderCompletePage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &OrderCompletePage{
+		LoggedIn: loggedIn(doc),
+	}
+	page.Errors, _ = alerts(doc)
+
+	if match := reOrderNumber.FindStringSubmatch(text(doc)); 2 == len(match) {
+		page.OrderNumber = match[1]
+	}
+
+	return
+}
@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseOrderComplete(t *testing.T) {
+	for _, name := range []string{"order_complete"} {
+		page, err := ParseOrderComplete(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
@@ -0,0 +1,56 @@
+package scrape
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	"golang.org/x/net/html"
+)
+
+// 订单号，例如 Your Order Number is: 1234567890
+var reOrderNumber = regexp.MustCompile(`Order Number is:?\s*(\d+)`)
+
+// RenewalResultPage domains.php?submitrenewals=true 提交续期后的页面
+type RenewalResultPage struct {
+	LoggedIn    bool   `json:"logged_in"`
+	Success     bool   `json:"success"`      // 页面中任何位置都没有 Order Confirmation 时续期成功
+	OrderNumber string `json:"order_number"` // 续期成功时的订单号
+	Message     string `json:"message"`      // 续期失败时的提示信息
+}
+
+// ParseRenewalResult 解析提交续期后的页面
+// Order Confirmation 出现在响应中的任何位置都视为续期失败，失败页面不一定把它放在标题中
+func ParseRenewalResult(r io.Reader) (page *RenewalResultPage, err error) {
+	var all []byte
+	all, err = ioutil.ReadAll(r)
+	if nil != err {
+		err = fmt.Errorf("scrape ReadAll err: %w", err)
+		return
+	}
+
+	var doc *html.Node
+	doc, err = parse(bytes.NewReader(all))
+	if nil != err {
+		return
+	}
+
+	page = &RenewalResultPage{
+		LoggedIn: loggedIn(doc),
+		Success:  !bytes.Contains(all, []byte("Order Confirmation")),
+	}
+
+	if match := reOrderNumber.FindStringSubmatch(text(doc)); 2 == len(match) {
+		page.OrderNumber = match[1]
+	}
+
+	if alert := find(doc, func(n *html.Node) bool {
+		return hasClass(n, "alert")
+	}); nil != alert {
+		page.Message = text(alert)
+	}
+
+	return
+}
@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseRenewalResult(t *testing.T) {
+	for _, name := range []string{"renewal_success", "renewal_failed", "renewal_failed_body"} {
+		page, err := ParseRenewalResult(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
@@ -0,0 +1,81 @@
+package scrape
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// RenewalRow 可续期域名列表中的一行
+type RenewalRow struct {
+	Domain   string `json:"domain"`
+	Status   string `json:"status"`
+	DaysLeft int    `json:"days_left"` // 距离到期的天数
+	ID       string `json:"id"`
+}
+
+// RenewalsPage domains.php?a=renewals 可续期域名列表页
+type RenewalsPage struct {
+	LoggedIn bool          `json:"logged_in"`
+	Domains  []*RenewalRow `json:"domains"`
+}
+
+// ParseRenewals 解析可续期域名列表页
+// 只识别带有 a=renewdomain 续期链接的行，剩余天数取自第三列中的 span
+func ParseRenewals(r io.Reader) (page *RenewalsPage, err error) {
+	var doc *html.Node
+	doc, err = parse(r)
+	if nil != err {
+		return
+	}
+
+	page = &RenewalsPage{
+		LoggedIn: loggedIn(doc),
+	}
+
+	rows := findAll(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Tr)
+	})
+
+	for _, tr := range rows {
+		cells := children(tr, atom.Td)
+		if len(cells) < 4 {
+			continue
+		}
+
+		a := find(tr, func(n *html.Node) bool {
+			return isElement(n, atom.A) && "renewdomain" == queryParam(attr(n, "href"), "a")
+		})
+		if nil == a {
+			continue
+		}
+
+		row := &RenewalRow{
+			Domain: text(cells[0]),
+			Status: text(cells[1]),
+			ID:     queryParam(attr(a, "href"), "domain"),
+		}
+
+		days := text(cells[2])
+		if span := find(cells[2], func(n *html.Node) bool {
+			return isElement(n, atom.Span)
+		}); nil != span {
+			days = text(span)
+		}
+
+		if fields := strings.Fields(days); 0 != len(fields) {
+			if row.DaysLeft, err = strconv.Atoi(fields[0]); nil != err {
+				err = fmt.Errorf("ParseRenewals Atoi days %s err: %w", days, err)
+				return
+			}
+		}
+
+		page.Domains = append(page.Domains, row)
+	}
+
+	return
+}
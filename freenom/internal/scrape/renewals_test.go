@@ -0,0 +1,14 @@
+package scrape
+
+import "testing"
+
+func TestParseRenewals(t *testing.T) {
+	for _, name := range []string{"renewals"} {
+		page, err := ParseRenewals(fixture(t, name))
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+
+		golden(t, name, page)
+	}
+}
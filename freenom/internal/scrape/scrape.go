@@ -0,0 +1,235 @@
+// Package scrape 使用 HTML 解析器从 Freenom 页面中提取数据
+//
+// 每个页面对应一个解析函数，返回的字段均已完成 HTML 实体解码，
+// 不依赖属性顺序及空白字符
+package scrape
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// parse 解析 HTML 文档
+func parse(r io.Reader) (doc *html.Node, err error) {
+	doc, err = html.Parse(r)
+	if nil != err {
+		err = fmt.Errorf("scrape Parse err: %w", err)
+	}
+
+	return
+}
+
+// attr 获取元素的属性值，不存在时返回空字符串
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// hasClass 判断元素的 class 属性中是否含有指定的类名
+func hasClass(n *html.Node, class string) bool {
+	for _, v := range strings.Fields(attr(n, "class")) {
+		if v == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isElement 判断节点是否为指定标签的元素
+func isElement(n *html.Node, a atom.Atom) bool {
+	return html.ElementNode == n.Type && a == n.DataAtom
+}
+
+// find 深度优先查找第一个满足条件的节点（不含 n 本身）
+func find(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if match(c) {
+			return c
+		}
+
+		if v := find(c, match); nil != v {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// findAll 深度优先查找所有满足条件的节点（不含 n 本身），匹配的节点不再向下查找
+func findAll(n *html.Node, match func(*html.Node) bool) (nodes []*html.Node) {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if match(c) {
+			nodes = append(nodes, c)
+			continue
+		}
+
+		nodes = append(nodes, findAll(c, match)...)
+	}
+
+	return
+}
+
+// children 获取指定标签的直接子元素
+func children(n *html.Node, a atom.Atom) (nodes []*html.Node) {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if isElement(c, a) {
+			nodes = append(nodes, c)
+		}
+	}
+
+	return
+}
+
+// text 获取节点内的文本，连续的空白字符合并为一个空格
+func text(n *html.Node) string {
+	var buf bytes.Buffer
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if html.TextNode == n.Type {
+			buf.WriteString(n.Data)
+			buf.WriteByte(' ')
+			return
+		}
+
+		for c := n.FirstChild; nil != c; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// queryParam 获取链接中的 URL 参数
+func queryParam(href, key string) string {
+	u, err := url.Parse(href)
+	if nil != err {
+		return ""
+	}
+
+	return u.Query().Get(key)
+}
+
+// formValues 获取表单中各输入项的当前值
+// 包括 input（单选框、复选框只取选中的项）、select（取选中的项，没有时取第一项）及 textarea
+func formValues(form *html.Node) url.Values {
+	values := url.Values{}
+
+	for _, n := range findAll(form, func(n *html.Node) bool {
+		return isElement(n, atom.Input) || isElement(n, atom.Select) || isElement(n, atom.Textarea)
+	}) {
+		name := attr(n, "name")
+		if "" == name {
+			continue
+		}
+
+		switch n.DataAtom {
+		case atom.Input:
+			switch strings.ToLower(attr(n, "type")) {
+			case "radio", "checkbox":
+				if !hasAttr(n, "checked") {
+					continue
+				}
+
+			case "submit", "button", "image", "reset":
+				continue
+			}
+
+			values.Add(name, attr(n, "value"))
+
+		case atom.Select:
+			options := findAll(n, func(o *html.Node) bool {
+				return isElement(o, atom.Option)
+			})
+
+			var selected *html.Node
+			for _, o := range options {
+				if hasAttr(o, "selected") {
+					selected = o
+					break
+				}
+			}
+
+			if nil == selected && 0 != len(options) {
+				selected = options[0]
+			}
+
+			if nil != selected {
+				if hasAttr(selected, "value") {
+					values.Add(name, attr(selected, "value"))
+				} else {
+					values.Add(name, text(selected))
+				}
+			}
+
+		case atom.Textarea:
+			var buf strings.Builder
+			for c := n.FirstChild; nil != c; c = c.NextSibling {
+				if html.TextNode == c.Type {
+					buf.WriteString(c.Data)
+				}
+			}
+
+			values.Add(name, buf.String())
+		}
+	}
+
+	return values
+}
+
+// hasAttr 判断元素是否带有指定属性
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// alerts 获取页面中的提示信息
+// 返回 class="alert-danger" 的错误信息及 class="alert-success" 的成功信息
+func alerts(doc *html.Node) (errs, success []string) {
+	for _, n := range findAll(doc, func(n *html.Node) bool {
+		return html.ElementNode == n.Type && (hasClass(n, "alert-danger") || hasClass(n, "alert-success"))
+	}) {
+		if hasClass(n, "alert-danger") {
+			errs = append(errs, text(n))
+		} else {
+			success = append(success, text(n))
+		}
+	}
+
+	return
+}
+
+// loggedIn 判断页头是否带有已登录的 Hello 标记
+func loggedIn(doc *html.Node) bool {
+	return nil != find(doc, func(n *html.Node) bool {
+		return isElement(n, atom.Span) && hasClass(n, "hidden-sm") && strings.HasPrefix(text(n), "Hello")
+	})
+}
+
+// LoggedIn 判断页面是否为已登录状态
+func LoggedIn(page []byte) bool {
+	doc, err := parse(bytes.NewReader(page))
+	if nil != err {
+		return false
+	}
+
+	return loggedIn(doc)
+}
@@ -0,0 +1,77 @@
+package scrape
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// go test ./freenom/internal/scrape -update 重新生成 .golden 文件
+var update = flag.Bool("update", false, "update .golden files")
+
+// fixture 打开 testdata 中的页面
+func fixture(t *testing.T, name string) *os.File {
+	f, err := os.Open(filepath.Join("testdata", name+".html"))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+// golden 比较解析结果与 testdata 中的 .golden 文件
+func golden(t *testing.T, name string, v interface{}) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	got := buf.Bytes()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); nil != err {
+			t.Fatal(err.Error())
+		}
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestLoggedIn(t *testing.T) {
+	if LoggedIn([]byte(`<span class="hidden-sm">Sign in</span>`)) {
+		t.Error("Sign in page reported as logged in")
+	}
+
+	if !LoggedIn([]byte(`<span class="visible-lg hidden-sm">
+		Hello <b>freenomapi</b></span>`)) {
+		t.Error("Hello marker not found")
+	}
+}
+
+func TestText(t *testing.T) {
+	doc, err := parse(strings.NewReader("<p> a\n\t<b>b &amp; c</b>  d </p>"))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if v := text(doc); "a b & c d" != v {
+		t.Errorf("unexpected text %q", v)
+	}
+}
@@ -0,0 +1,205 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+// 可以设置的自定义域名服务器数
+const (
+	minNameservers int = 2 // 委托域名至少需要 2 个域名服务器
+	maxNameservers int = 5
+)
+
+// Nameservers 域名使用的域名服务器
+type Nameservers struct {
+	Custom bool     // 是否使用自定义域名服务器，为 false 时使用 Freenom 默认域名服务器
+	Hosts  []string // 域名服务器主机名
+}
+
+// nameserversPath 域名服务器设置页的地址
+func nameserversPath(domainID string) (path string, query url.Values) {
+	query = url.Values{}
+	query.Add("action", "domaindetails")
+	query.Add("id", domainID)
+	query.Add("modop", "custom")
+	query.Add("a", "nameservers")
+	return loginPath, query
+}
+
+// validHostname 判断是否为合法的主机名
+func validHostname(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	if "" == host || len(host) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if "" == label || len(label) > 63 || '-' == label[0] || '-' == label[len(label)-1] {
+			return false
+		}
+
+		for _, ch := range label {
+			if !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9' || '-' == ch) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// GetNameservers 获取域名当前使用的域名服务器
+// 等同于使用 context.Background() 调用 GetNameserversContext
+func (c *Client) GetNameservers(domain string) (ns *Nameservers, err error) {
+	return c.GetNameserversContext(context.Background(), domain)
+}
+
+// GetNameserversContext 获取域名当前使用的域名服务器
+// 使用 Freenom 默认域名服务器时，Hosts 为页面上显示的默认域名服务器
+// ctx 被取消时中止请求及重试
+func (c *Client) GetNameserversContext(ctx context.Context, domain string) (ns *Nameservers, err error) {
+	if jar, _ := c.session(); nil == jar {
+		return nil, ErrNotLoggedIn
+	}
+
+	var info *DomainInfo
+	info, err = c.lookupDomain(ctx, domain)
+	if nil != err {
+		return
+	}
+
+	path, query := nameserversPath(info.DomainID)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    "GetNameservers",
+		path:    path,
+		query:   query,
+		referer: loginPath + "?action=domaindetails&id=" + info.DomainID,
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.NameserversPage
+	page, err = scrape.ParseNameservers(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("GetNameservers %w", err)
+		return
+	}
+
+	if "" == page.Choice {
+		err = fmt.Errorf("GetNameservers choice not found: %w", ErrUnexpectedResponse)
+		return
+	}
+
+	ns = &Nameservers{
+		Custom: "custom" == page.Choice,
+		Hosts:  page.Nameservers,
+	}
+	return
+}
+
+// SetNameservers 将域名委托给自定义域名服务器
+// 等同于使用 context.Background() 调用 SetNameserversContext
+func (c *Client) SetNameservers(domain string, hosts []string) (err error) {
+	return c.SetNameserversContext(context.Background(), domain, hosts)
+}
+
+// SetNameserversContext 将域名委托给自定义域名服务器，例如 Cloudflare 或自建的 BIND
+// 参数 hosts 域名服务器主机名，最少 2 个，最多 5 个
+// ctx 被取消时中止请求及重试
+func (c *Client) SetNameserversContext(ctx context.Context, domain string, hosts []string) (err error) {
+	if len(hosts) < minNameservers || len(hosts) > maxNameservers {
+		return fmt.Errorf("SetNameservers %d nameservers: %w", len(hosts), ErrInvalidNameservers)
+	}
+
+	for _, host := range hosts {
+		if !validHostname(host) {
+			return fmt.Errorf("SetNameservers %q: %w", host, ErrInvalidNameservers)
+		}
+	}
+
+	return c.saveNameservers(ctx, "SetNameservers", domain, "custom", hosts)
+}
+
+// UseDefaultNameservers 将域名切换回 Freenom 默认域名服务器
+// 等同于使用 context.Background() 调用 UseDefaultNameserversContext
+func (c *Client) UseDefaultNameservers(domain string) (err error) {
+	return c.UseDefaultNameserversContext(context.Background(), domain)
+}
+
+// UseDefaultNameserversContext 将域名切换回 Freenom 默认域名服务器
+// ctx 被取消时中止请求及重试
+func (c *Client) UseDefaultNameserversContext(ctx context.Context, domain string) (err error) {
+	return c.saveNameservers(ctx, "UseDefaultNameservers", domain, "default", nil)
+}
+
+// saveNameservers 提交域名服务器设置
+// 参数 choice 为 default 或 custom
+func (c *Client) saveNameservers(ctx context.Context, op, domain, choice string, hosts []string) (err error) {
+	jar, token := c.session()
+	if nil == jar {
+		return ErrNotLoggedIn
+	}
+
+	var info *DomainInfo
+	info, err = c.lookupDomain(ctx, domain)
+	if nil != err {
+		return
+	}
+
+	params := url.Values{}
+	params.Add("token", token)
+	params.Add("sub", "savens")
+	params.Add("nschoice", choice)
+	for i := 0; i < maxNameservers; i++ {
+		var host string
+		if i < len(hosts) {
+			host = hosts[i]
+		}
+
+		params.Add(fmt.Sprintf("ns%d", i+1), host)
+	}
+
+	path, query := nameserversPath(info.DomainID)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    op,
+		method:  "POST",
+		path:    path,
+		query:   query,
+		form:    params,
+		referer: path + "?" + query.Encode(),
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.NameserversPage
+	page, err = scrape.ParseNameservers(bytes.NewReader(all))
+	if nil != err {
+		return fmt.Errorf("%s %w", op, err)
+	}
+
+	if 0 != len(page.Errors) {
+		return &ManageError{
+			Op:      op,
+			Domain:  domain,
+			Message: strings.Join(page.Errors, "; "),
+		}
+	}
+
+	if 0 == len(page.Success) {
+		return fmt.Errorf("%s not success: %w", op, ErrUnexpectedResponse)
+	}
+
+	return
+}
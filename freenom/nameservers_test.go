@@ -0,0 +1,63 @@
+package freenom
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
+)
+
+func TestNameservers(t *testing.T) {
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	c := DefaultClient()
+	ns, err := c.GetNameservers(freenomDomain)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if ns.Custom || !reflect.DeepEqual(freenomtest.DefaultNameservers, ns.Hosts) {
+		t.Errorf("expect default nameservers, got %+v", ns)
+	}
+
+	hosts := []string{"ADA.NS.CLOUDFLARE.COM", "KIRK.NS.CLOUDFLARE.COM"}
+	if err = c.SetNameservers(freenomDomain, hosts); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if d, _ := srv.Domain(freenomUser, freenomDomain); !reflect.DeepEqual(hosts, d.Nameservers) {
+		t.Errorf("nameservers not saved on server: %v", d.Nameservers)
+	}
+
+	if ns, err = c.GetNameservers(freenomDomain); nil != err {
+		t.Fatal(err.Error())
+	} else if !ns.Custom || !reflect.DeepEqual(hosts, ns.Hosts) {
+		t.Errorf("expect custom nameservers %v, got %+v", hosts, ns)
+	}
+
+	if err = c.UseDefaultNameservers(freenomDomain); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if d, _ := srv.Domain(freenomUser, freenomDomain); 0 != len(d.Nameservers) {
+		t.Errorf("nameservers not reset on server: %v", d.Nameservers)
+	}
+
+	for _, bad := range [][]string{nil, {"ns1.example.com"}, {"ns1.example.com", "-bad-.example.com"}, make([]string, 6)} {
+		if err = c.SetNameservers(freenomDomain, bad); !errors.Is(err, ErrInvalidNameservers) {
+			t.Errorf("expect ErrInvalidNameservers for %q, got %v", bad, err)
+		}
+	}
+
+	// 服务器拒绝时返回 ManageError，绕过参数检查提交 1 个域名服务器
+	var me *ManageError
+	if err = c.saveNameservers(context.Background(), "SetNameservers", freenomDomain, "custom", []string{"ns1.example.com"}); !errors.As(err, &me) {
+		t.Errorf("expect ManageError, got %v", err)
+	}
+}
@@ -0,0 +1,650 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+const addToCartPath string = "includes/domains/fn-additional.php"
+const confDomainsPath string = "cart.php?a=confdomains"
+const cartViewPath string = "cart.php?a=view"
+const checkoutPath string = "cart.php?a=checkout"
+const domainPricingPath string = "includes/domains/confdomain-pricing.php"
+const domainUpdatePath string = "includes/domains/confdomain-update.php"
+const domainConfigurePath string = "includes/domains/domainconfigure.php"
+const captchaCheckPath string = "includes/custom/cap_chk.php"
+
+// PurchaseOptions 购买域名时的初始设置
+// Nameservers 与 ForwardURL 只能设置其中一项，都不设置时使用 Freenom DNS
+type PurchaseOptions struct {
+	Nameservers []string // 使用自定义域名服务器，2 至 5 个
+	ForwardURL  string   // 将域名转发到该地址，必须是 http 或 https 地址
+}
+
+// addToCartResult fn-additional.php 的响应
+type addToCartResult struct {
+	Available int `json:"available"`
+}
+
+// domainPrice confdomain-pricing.php 返回的某个注册时长的价格
+type domainPrice struct {
+	Period   string `json:"period"` // 例如 12M
+	Price    string `json:"price"`  // 例如 0.00
+	Currency string `json:"currency"`
+}
+
+// domainPricing confdomain-pricing.php 返回的域名价格表
+type domainPricing struct {
+	DomainType string         `json:"domaintype"`
+	DomainName string         `json:"domainname"`
+	Pricing    []*domainPrice `json:"pricing"`
+}
+
+// configureResult confdomain-update.php 及 domainconfigure.php 的响应
+type configureResult struct {
+	Status string            `json:"status"`
+	Errors map[string]string `json:"errors"` // 键为 域名_字段，例如 freenom-api.tk_urlfwd
+}
+
+// message 将各字段的错误信息按字段排序后合并
+func (r *configureResult) message() string {
+	keys := make([]string, 0, len(r.Errors))
+	for k := range r.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msgs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		msgs = append(msgs, k+": "+r.Errors[k])
+	}
+
+	if 0 == len(msgs) {
+		return "status " + r.Status
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// splitDomain 将域名拆分为前缀及后缀，例如 freenom-api.tk 拆分为 freenom-api 与 .tk
+func splitDomain(domain string) (prefix, tld string, ok bool) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	i := strings.Index(domain, ".")
+	if i <= 0 || i == len(domain)-1 {
+		return "", "", false
+	}
+
+	return domain[:i], domain[i:], true
+}
+
+// periodField 域名配置表单中注册时长字段的名称
+// 与 domainconfigure.js 一致，只将域名中的第一个 . 替换为 _，例如 freenom-api_tk_period
+func periodField(domain string) string {
+	return strings.Replace(domain, ".", "_", 1) + "_period"
+}
+
+// PurchaseFreeDomain 购买免费域名
+// 等同于使用 context.Background() 调用 PurchaseFreeDomainContext
+func (c *Client) PurchaseFreeDomain(domain string, months int, opts *PurchaseOptions) (orderNumber string, err error) {
+	return c.PurchaseFreeDomainContext(context.Background(), domain, months, opts)
+}
+
+// PurchaseFreeDomainContext 购买免费域名，按浏览器中的顺序完成查询、加入购物车、配置及结账
+// 网站做了 GOOGLE 的反机器人校验（reCAPTCHA v3），需要通过 WithCaptchaSolver 设置 CaptchaSolver
+// 需要注意：Freenom 账号的区域要与发起购买请求的 IP 的地理位置保持一致
+// 可以访问 http://my.freenom.com/details/js/dynamiccountry.php 获取当前 IP 的地区名称英文缩写
+// 参数 months 注册月数，1 至 12
+// 参数 opts 初始的域名服务器或 URL 转发设置，为 nil 时使用 Freenom DNS
+// 返回 订单号
+// ctx 被取消时中止请求及重试
+func (c *Client) PurchaseFreeDomainContext(ctx context.Context, domain string, months int, opts *PurchaseOptions) (orderNumber string, err error) {
+	const op = "PurchaseFreeDomain"
+
+	if months < 1 || months > 12 {
+		err = ErrInvalidPeriod
+		return
+	}
+
+	if nil == opts {
+		opts = &PurchaseOptions{}
+	}
+
+	var config map[string]string
+	config, err = purchaseConfig(op, opts)
+	if nil != err {
+		return
+	}
+
+	prefix, tld, ok := splitDomain(domain)
+	if !ok {
+		err = fmt.Errorf("%s invalid domain %q: %w", op, domain, ErrDomainUnavailable)
+		return
+	}
+	domain = prefix + tld
+
+	if jar, _ := c.session(); nil == jar {
+		err = ErrNotLoggedIn
+		return
+	}
+
+	c.mu.Lock()
+	solver := c.captchaSolver
+	c.mu.Unlock()
+
+	if nil == solver { // 在修改购物车之前发现没有设置 CaptchaSolver
+		err = fmt.Errorf("%s: %w", op, ErrNoCaptchaSolver)
+		return
+	}
+
+	// 1. 查询域名是否可以免费注册
+	var status *DomainStatus
+	status, err = c.freeDomainStatus(ctx, op, prefix, tld)
+	if nil != err {
+		return
+	}
+
+	// 2. 加入购物车
+	if 0 == status.IsInCart {
+		if err = c.addToCart(ctx, op, prefix, tld); nil != err {
+			return
+		}
+	}
+
+	// 3. 打开域名配置页
+	var conf *scrape.ConfDomainsPage
+	conf, err = c.confDomains(ctx, op)
+	if nil != err {
+		return
+	}
+
+	found := false
+	for _, d := range conf.Domains {
+		if d.Domain == domain {
+			found = true
+		} else { // 不提交购物车中已有的其它域名
+			err = &ManageError{
+				Op:      op,
+				Domain:  domain,
+				Message: "cart contains other domain " + d.Domain,
+			}
+			return
+		}
+	}
+
+	if !found {
+		err = fmt.Errorf("%s %s not in cart: %w", op, domain, ErrUnexpectedResponse)
+		return
+	}
+
+	// 4. 确认选择的注册时长是免费的
+	period := fmt.Sprintf("%dM", months)
+	if err = c.checkFreePeriod(ctx, op, domain, period); nil != err {
+		return
+	}
+
+	// 5. 域名配置页加载时的 reCAPTCHA 校验
+	if err = c.submitCaptcha(ctx, op, conf.CaptchaSiteKey, captchaActionConfDomains, confDomainsPath); nil != err {
+		return
+	}
+
+	// 6. 修改注册时长
+	params := url.Values{}
+	params.Add("domain", domain)
+	params.Add("period", period)
+	if err = c.updateCartDomain(ctx, op, domain, params); nil != err {
+		return
+	}
+
+	// 7. 提交域名服务器或 URL 转发设置
+	if err = c.configureCartDomain(ctx, op, domain, config); nil != err {
+		return
+	}
+
+	// 8. 提交配置表单，跳转到购物车页
+	var cart *scrape.CartPage
+	cart, err = c.submitConfDomains(ctx, op, domain, conf, map[string]string{domain: period}, opts.Nameservers)
+	if nil != err {
+		return
+	}
+
+	// 9. 确认购物车中只有该免费域名
+	if err = checkFreeCart(op, domain, cart); nil != err {
+		return
+	}
+
+	// 10. 结账
+	return c.checkout(ctx, op, domain, cart)
+}
+
+// checkFreeCart 确认购物车中只有 domain 且应付总额为零
+// 避免之前加入购物车的收费项目（例如浏览器中加入的）随免费域名一起结账
+func checkFreeCart(op, domain string, cart *scrape.CartPage) (err error) {
+	if "" == strings.TrimSpace(cart.Total) {
+		return fmt.Errorf("%s cart total not found: %w", op, ErrUnexpectedResponse)
+	}
+
+	var total Price
+	if total, err = parsePrice(cart.Total); nil != err {
+		return fmt.Errorf("%s cart total %w", op, err)
+	}
+
+	msg := ""
+	for _, item := range cart.Items {
+		if !strings.EqualFold(domain, item.Domain) {
+			msg = "cart contains other item " + strings.TrimSpace(item.Description)
+			break
+		}
+	}
+
+	if "" == msg && 1 != len(cart.Items) {
+		msg = fmt.Sprintf("cart contains %d items", len(cart.Items))
+	} else if "" == msg && !total.IsFree() {
+		msg = "cart total is " + total.String()
+	}
+
+	if "" != msg {
+		err = &ManageError{
+			Op:      op,
+			Domain:  domain,
+			Message: msg,
+		}
+	}
+
+	return
+}
+
+// purchaseConfig 检查购买设置，并转换为提交到 domainconfigure.php 的字段
+// 字段名与域名配置页中输入框 id 的后半部分一致：urlfwd 为转发地址，dnN/diN 为第 N 个域名服务器及其 IP
+func purchaseConfig(op string, opts *PurchaseOptions) (config map[string]string, err error) {
+	config = make(map[string]string)
+
+	if 0 != len(opts.Nameservers) && "" != opts.ForwardURL {
+		err = fmt.Errorf("%s nameservers and forward url are exclusive: %w", op, ErrInvalidForwarding)
+		return
+	}
+
+	if "" != opts.ForwardURL {
+		u, e := url.Parse(opts.ForwardURL)
+		if nil != e || ("http" != strings.ToLower(u.Scheme) && "https" != strings.ToLower(u.Scheme)) || "" == u.Host {
+			err = fmt.Errorf("%s %q: %w", op, opts.ForwardURL, ErrInvalidForwarding)
+			return
+		}
+
+		config["urlfwd"] = opts.ForwardURL
+		return
+	}
+
+	if 0 != len(opts.Nameservers) && (len(opts.Nameservers) < minNameservers || len(opts.Nameservers) > maxNameservers) {
+		err = fmt.Errorf("%s %d nameservers: %w", op, len(opts.Nameservers), ErrInvalidNameservers)
+		return
+	}
+
+	for i, host := range opts.Nameservers {
+		if !validHostname(host) {
+			err = fmt.Errorf("%s %q: %w", op, host, ErrInvalidNameservers)
+			return
+		}
+
+		config["dn"+strconv.Itoa(i+1)] = host
+		config["di"+strconv.Itoa(i+1)] = ""
+	}
+
+	return
+}
+
+// freeDomainStatus 使用当前会话查询域名，域名必须可以免费注册
+// 与 CheckFreeDomainPurchasable 不同，查询结果中的 is_in_cart 反映当前会话的购物车
+func (c *Client) freeDomainStatus(ctx context.Context, op, prefix, tld string) (status *DomainStatus, err error) {
+	var domainList *DomainListResult
+	domainList, err = c.checkAvailable(ctx, op+" CheckAvailable", prefix, "")
+	if nil != err {
+		return
+	}
+
+	for _, v := range domainList.FreeDomains {
+		if !strings.EqualFold(tld, v.TLD) {
+			continue
+		}
+
+		if !strings.EqualFold("AVAILABLE", v.Status) || !strings.EqualFold("FREE", v.Type) {
+			break
+		}
+
+		return v, nil
+	}
+
+	err = fmt.Errorf("%s %s%s: %w", op, prefix, tld, ErrDomainUnavailable)
+	return
+}
+
+// addToCart 将域名加入购物车，即查询结果中的 Get it now!
+func (c *Client) addToCart(ctx context.Context, op, prefix, tld string) (err error) {
+	params := url.Values{}
+	params.Add("domain", prefix)
+	params.Add("tld", tld)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:         op + " AddToCart",
+		method:       "POST",
+		path:         addToCartPath,
+		form:         params,
+		referer:      domainsPath,
+		noLoginCheck: true,
+	})
+	if nil != err {
+		return
+	}
+
+	var result addToCartResult
+	if err = json.Unmarshal(all, &result); nil != err {
+		return fmt.Errorf("%s Unmarshal add to cart err: %w", op, err)
+	}
+
+	if 1 != result.Available {
+		return fmt.Errorf("%s %s%s: %w", op, prefix, tld, ErrDomainUnavailable)
+	}
+
+	return
+}
+
+// confDomains 获取购物车中的域名配置页
+func (c *Client) confDomains(ctx context.Context, op string) (page *scrape.ConfDomainsPage, err error) {
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    op + " ConfDomains",
+		path:    confDomainsPath,
+		referer: domainsPath,
+	})
+	if nil != err {
+		return
+	}
+
+	page, err = scrape.ParseConfDomains(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("%s %w", op, err)
+		return
+	}
+
+	if nil == page.Form || "" == page.Form.Get("token") {
+		err = fmt.Errorf("%s configuration form not found: %w", op, ErrUnexpectedResponse)
+		return
+	}
+
+	return
+}
+
+// domainPricing 获取购物车中域名各注册时长的价格
+// 返回 以小写域名为键的价格表
+func (c *Client) domainPricing(ctx context.Context, op string, domains []string) (pricing map[string]*domainPricing, err error) {
+	params := url.Values{}
+	for _, d := range domains {
+		params.Add("domains[]", d)
+	}
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:         op + " DomainPricing",
+		method:       "POST",
+		path:         domainPricingPath,
+		form:         params,
+		referer:      confDomainsPath,
+		noLoginCheck: true,
+	})
+	if nil != err {
+		return
+	}
+
+	var result map[string]*domainPricing
+	if err = json.Unmarshal(all, &result); nil != err {
+		err = fmt.Errorf("%s Unmarshal pricing err: %w", op, err)
+		return
+	}
+
+	pricing = make(map[string]*domainPricing)
+	for k, v := range result { // 键为大写的域名
+		pricing[strings.ToLower(k)] = v
+	}
+
+	return
+}
+
+// checkFreePeriod 检查域名在指定注册时长下是否免费
+func (c *Client) checkFreePeriod(ctx context.Context, op, domain, period string) (err error) {
+	var pricing map[string]*domainPricing
+	pricing, err = c.domainPricing(ctx, op, []string{domain})
+	if nil != err {
+		return
+	}
+
+	p, ok := pricing[domain]
+	if !ok {
+		return fmt.Errorf("%s %s pricing not found: %w", op, domain, ErrUnexpectedResponse)
+	}
+
+	for _, v := range p.Pricing {
+		if period != v.Period {
+			continue
+		}
+
+		price, e := strconv.ParseFloat(v.Price, 64)
+		if nil != e {
+			return fmt.Errorf("%s %s invalid price %q: %w", op, domain, v.Price, ErrUnexpectedResponse)
+		}
+
+		if 0 != price {
+			return &ManageError{
+				Op:      op,
+				Domain:  domain,
+				Message: fmt.Sprintf("period %s costs %s %s", period, v.Currency, v.Price),
+			}
+		}
+
+		return nil
+	}
+
+	return &ManageError{
+		Op:      op,
+		Domain:  domain,
+		Message: fmt.Sprintf("period %s not available", period),
+	}
+}
+
+// updateCartDomain 修改购物车中域名的注册时长或 ID Shield 设置
+func (c *Client) updateCartDomain(ctx context.Context, op, domain string, params url.Values) (err error) {
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:         op + " UpdateDomain",
+		method:       "POST",
+		path:         domainUpdatePath,
+		form:         params,
+		referer:      confDomainsPath,
+		noLoginCheck: true,
+	})
+	if nil != err {
+		return
+	}
+
+	return checkConfigureResult(op, domain, all)
+}
+
+// configureCartDomain 提交购物车中域名的域名服务器或 URL 转发设置
+// 参数 config 为空时与浏览器中不做任何选择一致，提交 {}
+func (c *Client) configureCartDomain(ctx context.Context, op, domain string, config map[string]string) (err error) {
+	data := make(map[string]map[string]string)
+	if 0 != len(config) {
+		data[domain] = config
+	}
+
+	var buf []byte
+	buf, err = json.Marshal(data)
+	if nil != err {
+		return fmt.Errorf("%s Marshal config err: %w", op, err)
+	}
+
+	params := url.Values{}
+	params.Add("data", string(buf))
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:         op + " ConfigureDomain",
+		method:       "POST",
+		path:         domainConfigurePath,
+		form:         params,
+		referer:      confDomainsPath,
+		noLoginCheck: true,
+	})
+	if nil != err {
+		return
+	}
+
+	return checkConfigureResult(op, domain, all)
+}
+
+// checkConfigureResult 检查 confdomain-update.php 及 domainconfigure.php 的响应
+func checkConfigureResult(op, domain string, all []byte) (err error) {
+	var result configureResult
+	if err = json.Unmarshal(all, &result); nil != err {
+		return fmt.Errorf("%s Unmarshal configure result err: %w", op, err)
+	}
+
+	if !strings.EqualFold("OK", result.Status) {
+		return &ManageError{
+			Op:      op,
+			Domain:  domain,
+			Message: result.message(),
+		}
+	}
+
+	return
+}
+
+// submitConfDomains 提交域名配置表单，成功后跳转到购物车页
+// 参数 periods 购物车中各域名的注册时长
+// 参数 nameservers 不为空时替换表单中的默认域名服务器
+func (c *Client) submitConfDomains(ctx context.Context, op, domain string, conf *scrape.ConfDomainsPage,
+	periods map[string]string, nameservers []string) (cart *scrape.CartPage, err error) {
+	params := url.Values{}
+	for k, v := range conf.Form {
+		params[k] = append([]string(nil), v...)
+	}
+
+	for d, period := range periods {
+		if "" != period {
+			params.Set(periodField(d), period)
+		}
+	}
+
+	if 0 != len(nameservers) {
+		for i := 0; i < maxNameservers; i++ {
+			var host string
+			if i < len(nameservers) {
+				host = nameservers[i]
+			}
+
+			params.Set(fmt.Sprintf("domainns%d", i+1), host)
+		}
+	}
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    op + " SubmitConfDomains",
+		method:  "POST",
+		path:    confDomainsPath,
+		form:    params,
+		referer: confDomainsPath,
+	})
+	if nil != err {
+		return
+	}
+
+	cart, err = scrape.ParseCart(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("%s %w", op, err)
+		return
+	}
+
+	if 0 != len(cart.Errors) {
+		err = &ManageError{
+			Op:      op,
+			Domain:  domain,
+			Message: strings.Join(cart.Errors, "; "),
+		}
+		return
+	}
+
+	return
+}
+
+// checkout 在购物车页提交结账表单
+// 表单中的 fpbb/iobb 是浏览器中由设备指纹脚本填写的字段，这里与页面中的初始值一样留空
+// 返回 订单号
+func (c *Client) checkout(ctx context.Context, op, domain string, cart *scrape.CartPage) (orderNumber string, err error) {
+	if nil == cart.Checkout || "" == cart.Checkout.Get("token") {
+		err = fmt.Errorf("%s checkout form not found: %w", op, ErrUnexpectedResponse)
+		return
+	}
+
+	// 购物车页加载时的 reCAPTCHA 校验
+	if err = c.submitCaptcha(ctx, op, cart.CaptchaSiteKey, captchaActionCheckout, cartViewPath); nil != err {
+		return
+	}
+
+	// 点击 Complete Order 时的 reCAPTCHA 令牌随表单一起提交
+	var token string
+	token, err = c.solveCaptcha(ctx, op, cart.CaptchaSiteKey, captchaActionSubmitCheckout, cartViewPath)
+	if nil != err {
+		return
+	}
+
+	params := url.Values{}
+	for k, v := range cart.Checkout {
+		params[k] = append([]string(nil), v...)
+	}
+	params.Set("accepttos", "on")
+	params.Set("captcha_tkn", token)
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    op + " Checkout",
+		method:  "POST",
+		path:    checkoutPath,
+		form:    params,
+		referer: cartViewPath,
+		noRetry: true,
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.OrderCompletePage
+	page, err = scrape.ParseOrderComplete(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("%s %w", op, err)
+		return
+	}
+
+	if "" != page.OrderNumber {
+		return page.OrderNumber, nil
+	}
+
+	if 0 != len(page.Errors) {
+		err = &ManageError{
+			Op:      op,
+			Domain:  domain,
+			Message: strings.Join(page.Errors, "; "),
+		}
+		return
+	}
+
+	err = fmt.Errorf("%s order number not found: %w", op, ErrUnexpectedResponse)
+	return
+}
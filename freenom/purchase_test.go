@@ -0,0 +1,267 @@
+package freenom
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom/freenomtest"
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+// testSolver 返回模拟服务器接受的令牌，并记录求解过的动作
+func testSolver(actions *[]string) CaptchaSolver {
+	return CaptchaSolverFunc(func(ctx context.Context, captcha *Captcha) (string, error) {
+		if freenomtest.CaptchaSiteKey != captcha.SiteKey {
+			return "", errors.New("unexpected site key " + captcha.SiteKey)
+		}
+
+		*actions = append(*actions, captcha.Action)
+		return freenomtest.CaptchaToken(captcha.Action), nil
+	})
+}
+
+func TestPurchaseFreeDomain(t *testing.T) {
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	c := DefaultClient()
+	if _, err := c.PurchaseFreeDomain("freenom-go.tk", 12, nil); !errors.Is(err, ErrNoCaptchaSolver) {
+		t.Errorf("expect ErrNoCaptchaSolver, got %v", err)
+	}
+
+	var actions []string
+	c.SetCaptchaSolver(testSolver(&actions))
+
+	orderNumber, err := c.PurchaseFreeDomain("Freenom-Go.tk", 12, nil)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if "" == orderNumber {
+		t.Error("empty order number")
+	}
+
+	want := []string{captchaActionConfDomains, captchaActionCheckout, captchaActionSubmitCheckout}
+	if !reflect.DeepEqual(want, actions) {
+		t.Errorf("expect captcha actions %v, got %v", want, actions)
+	}
+
+	d, ok := srv.Domain(freenomUser, "freenom-go.tk")
+	if !ok {
+		t.Fatal("domain not registered on server")
+	}
+
+	if exp := d.RegDate.AddDate(0, 12, 0); !d.ExpDate.Equal(exp) {
+		t.Errorf("expect 12 months registration, got %s - %s", d.RegDate, d.ExpDate)
+	}
+
+	// 已注册的域名不能再购买
+	if _, err = c.PurchaseFreeDomain("freenom-go.tk", 12, nil); !errors.Is(err, ErrDomainUnavailable) {
+		t.Errorf("expect ErrDomainUnavailable, got %v", err)
+	}
+
+	// 收费域名不能购买
+	if _, err = c.PurchaseFreeDomain("freenom-go.com", 12, nil); !errors.Is(err, ErrDomainUnavailable) {
+		t.Errorf("expect ErrDomainUnavailable for paid domain, got %v", err)
+	}
+
+	for _, months := range []int{0, 13} {
+		if _, err = c.PurchaseFreeDomain("freenom-go.ml", months, nil); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("expect ErrInvalidPeriod for %d months, got %v", months, err)
+		}
+	}
+}
+
+func TestPurchaseFreeDomainOptions(t *testing.T) {
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	var actions []string
+	c := DefaultClient()
+	c.SetCaptchaSolver(testSolver(&actions))
+
+	hosts := []string{"ADA.NS.CLOUDFLARE.COM", "KIRK.NS.CLOUDFLARE.COM"}
+	if _, err := c.PurchaseFreeDomain("freenom-ns.ga", 3, &PurchaseOptions{Nameservers: hosts}); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if d, _ := srv.Domain(freenomUser, "freenom-ns.ga"); !reflect.DeepEqual(hosts, d.Nameservers) {
+		t.Errorf("expect nameservers %v, got %v", hosts, d.Nameservers)
+	}
+
+	if _, err := c.PurchaseFreeDomain("freenom-fwd.cf", 1, &PurchaseOptions{ForwardURL: "https://example.com/"}); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if d, _ := srv.Domain(freenomUser, "freenom-fwd.cf"); nil == d.Forwarding || "https://example.com/" != d.Forwarding.URL {
+		t.Errorf("expect forwarding to https://example.com/, got %+v", d.Forwarding)
+	}
+
+	// 服务器拒绝配置时返回 ManageError，域名不会被注册
+	var me *ManageError
+	_, err := c.PurchaseFreeDomain("freenom-bad.gq", 1, &PurchaseOptions{ForwardURL: "https://freenom-bad.gq/"})
+	if !errors.As(err, &me) || !strings.Contains(me.Message, "_urlfwd") {
+		t.Errorf("expect ManageError, got %v", err)
+	}
+
+	if _, ok := srv.Domain(freenomUser, "freenom-bad.gq"); ok {
+		t.Error("domain registered after rejected configuration")
+	}
+
+	for _, bad := range [][]string{{"bad host", hosts[0]}, hosts[:1]} {
+		if _, err = c.PurchaseFreeDomain("freenom-bad.gq", 1, &PurchaseOptions{Nameservers: bad}); !errors.Is(err, ErrInvalidNameservers) {
+			t.Errorf("expect ErrInvalidNameservers for %q, got %v", bad, err)
+		}
+	}
+
+	for _, opts := range []*PurchaseOptions{
+		{ForwardURL: "ftp://example.com"},
+		{ForwardURL: "https://example.com/", Nameservers: hosts},
+	} {
+		if _, err = c.PurchaseFreeDomain("freenom-bad.gq", 1, opts); !errors.Is(err, ErrInvalidForwarding) {
+			t.Errorf("expect ErrInvalidForwarding for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func TestPurchaseOtherItemsInCart(t *testing.T) {
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	var actions []string
+	c := DefaultClient()
+	c.SetCaptchaSolver(testSolver(&actions))
+
+	// 之前加入购物车的收费域名不能随免费域名一起结账
+	if err := c.AddToCart("freenom-paid.com"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	var me *ManageError
+	if _, err := c.PurchaseFreeDomain("freenom-go.tk", 12, nil); !errors.As(err, &me) || !strings.Contains(me.Message, "freenom-paid.com") {
+		t.Errorf("expect ManageError, got %v", err)
+	}
+
+	for _, name := range []string{"freenom-go.tk", "freenom-paid.com"} {
+		if _, ok := srv.Domain(freenomUser, name); ok {
+			t.Errorf("%s ordered with other items in cart", name)
+		}
+	}
+
+	if 0 != len(actions) {
+		t.Errorf("captcha solved before the cart was checked: %v", actions)
+	}
+}
+
+func TestCheckFreeCart(t *testing.T) {
+	free := &scrape.CartRow{Description: "Domain Registration - freenom-go.tk", Domain: "freenom-go.tk", Price: "$0.00USD"}
+	paid := &scrape.CartRow{Description: "Domain Registration - freenom-paid.com", Domain: "freenom-paid.com", Price: "$8.38USD"}
+	other := &scrape.CartRow{Description: "Hosting", Price: "$0.00USD"}
+
+	if err := checkFreeCart("Purchase", "freenom-go.tk", &scrape.CartPage{Items: []*scrape.CartRow{free}, Total: "$0.00USD"}); nil != err {
+		t.Errorf("expect free cart accepted, got %v", err)
+	}
+
+	for _, cart := range []*scrape.CartPage{
+		{Items: []*scrape.CartRow{free, paid}, Total: "$8.38USD"},
+		{Items: []*scrape.CartRow{free, other}, Total: "$0.00USD"},
+		{Items: []*scrape.CartRow{free}, Total: "$9.95USD"},
+		{Items: []*scrape.CartRow{free, free}, Total: "$0.00USD"},
+		{Total: "$0.00USD"},
+	} {
+		var me *ManageError
+		if err := checkFreeCart("Purchase", "freenom-go.tk", cart); !errors.As(err, &me) {
+			t.Errorf("expect ManageError for %+v, got %v", cart, err)
+		}
+	}
+
+	for _, total := range []string{"", "free"} {
+		if err := checkFreeCart("Purchase", "freenom-go.tk", &scrape.CartPage{Items: []*scrape.CartRow{free}, Total: total}); !errors.Is(err, ErrUnexpectedResponse) {
+			t.Errorf("expect ErrUnexpectedResponse for total %q, got %v", total, err)
+		}
+	}
+}
+
+func TestPurchaseCaptchaRejected(t *testing.T) {
+	srv := newTestServer(t)
+
+	if err := Login(freenomUser, freenomPwd); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	c := DefaultClient()
+	c.SetCaptchaSolver(CaptchaSolverFunc(func(ctx context.Context, captcha *Captcha) (string, error) {
+		return "wrong token", nil
+	}))
+
+	var me *ManageError
+	if _, err := c.PurchaseFreeDomain("freenom-go.tk", 12, nil); !errors.As(err, &me) {
+		t.Errorf("expect ManageError, got %v", err)
+	}
+
+	if _, ok := srv.Domain(freenomUser, "freenom-go.tk"); ok {
+		t.Error("domain registered with rejected captcha")
+	}
+}
+
+func TestPromptCaptchaSolver(t *testing.T) {
+	var out strings.Builder
+	solver := PromptCaptchaSolver(strings.NewReader("  token-123 \n"), &out)
+
+	token, err := solver.SolveCaptcha(context.Background(), &Captcha{
+		SiteKey: "key",
+		Action:  captchaActionCheckout,
+		PageURL: "https://my.freenom.com/cart.php?a=view",
+	})
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if "token-123" != token || !strings.Contains(out.String(), captchaActionCheckout) {
+		t.Errorf("unexpected token %q, prompt %q", token, out.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	pr, pw := io.Pipe() // 没有输入时一直阻塞
+	defer pw.Close()
+
+	blocked := PromptCaptchaSolver(pr, &out)
+	if _, err = blocked.SolveCaptcha(ctx, &Captcha{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect context.DeadlineExceeded, got %v", err)
+	}
+
+	// 取消后的下一次求解读取到之后输入的令牌
+	go io.WriteString(pw, "token-456\n")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if token, err = blocked.SolveCaptcha(ctx, &Captcha{}); nil != err || "token-456" != token {
+		t.Errorf("expect token-456, got %q %v", token, err)
+	}
+
+	pw.Close()
+	if _, err = blocked.SolveCaptcha(ctx, &Captcha{}); !errors.Is(err, io.EOF) {
+		t.Errorf("expect io.EOF, got %v", err)
+	}
+
+	if _, err = blocked.SolveCaptcha(ctx, &Captcha{}); !errors.Is(err, io.EOF) {
+		t.Errorf("expect io.EOF after the reader is closed, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+)
+
+// 免费域名的续期状态
+const (
+	RenewalSuccess     string = "success"      // 续期成功
+	RenewalFailed      string = "failed"       // 提交续期时出错或网站拒绝续期，原因见 Renewal.Err
+	RenewalNotDue      string = "not_due"      // 距离到期超过 14 天，还不能续期
+	RenewalNotSelected string = "not_selected" // 可以续期，但不是参数 domain 指定的域名
+	RenewalPlanned     string = "planned"      // 将会续期，RenewFreeDomain 中途出错时表示还没有提交续期
+)
+
+// Renewal 一个免费域名的续期结果
+type Renewal struct {
+	Domain      string
+	DomainID    string
+	DaysLeft    int    // 距离到期的天数
+	Status      string // 见 RenewalSuccess 等常量
+	Months      int    // 请求续期的月份数，不会续期时为 0
+	OrderNumber string // 续期成功时网站返回的订单号，网站没有返回时为空
+	Err         error  // 续期失败的原因，网站拒绝续期时为 *ManageError，其它状态时为 nil
+}
+
+// RenewFreeDomain 免费域名续期
+// 等同于使用 context.Background() 调用 RenewFreeDomainContext
+func (c *Client) RenewFreeDomain(domain string, months int) (renewals []*Renewal, err error) {
+	return c.RenewFreeDomainContext(context.Background(), domain, months)
+}
+
+// RenewFreeDomainContext 免费域名续期
+// 参数 domain 若为空字符串，则续期所有域名，否则只续期指定域名
+// 参数 months 要续期的月份数，最少 1 个月，最多 12 个月
+// 返回 可续期域名列表中所有域名的续期结果，顺序与网站上的列表一致
+// 某个域名被网站拒绝续期不影响其它域名；请求出错时中止续期，返回已有的结果及错误
+// ctx 被取消时中止请求及重试
+func (c *Client) RenewFreeDomainContext(ctx context.Context, domain string, months int) (renewals []*Renewal, err error) {
+	if renewals, err = c.planRenewals(ctx, "RenewFreeDomain", domain, months); nil != err {
+		return
+	}
+
+	//////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+	err = c.submitRenewals(ctx, renewals)
+	return
+}
+
+// RenewPlanned 提交 PlanRenewFreeDomain 列出的续期
+// 等同于使用 context.Background() 调用 RenewPlannedContext
+func (c *Client) RenewPlanned(renewals []*Renewal) (err error) {
+	return c.RenewPlannedContext(context.Background(), renewals)
+}
+
+// RenewPlannedContext 提交 PlanRenewFreeDomain 列出的续期，只提交状态为 RenewalPlanned 的域名，结果写入各 Renewal
+// 提交前可以按域名修改 Months，或将 Status 改为 RenewalNotSelected 跳过该域名，不会再次读取可续期域名列表
+// 某个域名被网站拒绝续期不影响其它域名；请求出错时中止续期，返回错误，未提交的域名状态仍为 RenewalPlanned
+// ctx 被取消时中止请求及重试
+func (c *Client) RenewPlannedContext(ctx context.Context, renewals []*Renewal) (err error) {
+	if jar, _ := c.session(); nil == jar {
+		return ErrNotLoggedIn
+	}
+
+	for _, r := range renewals {
+		if RenewalPlanned == r.Status && (r.Months < 1 || r.Months > 12) {
+			return fmt.Errorf("RenewFreeDomain %s %d months: %w", r.Domain, r.Months, ErrInvalidPeriod)
+		}
+	}
+
+	return c.submitRenewals(ctx, renewals)
+}
+
+// PlanRenewFreeDomain 列出 RenewFreeDomain 将会续期及跳过的域名，不会提交续期
+// 等同于使用 context.Background() 调用 PlanRenewFreeDomainContext
+func (c *Client) PlanRenewFreeDomain(domain string, months int) (renewals []*Renewal, err error) {
+	return c.PlanRenewFreeDomainContext(context.Background(), domain, months)
+}
+
+// PlanRenewFreeDomainContext 列出 RenewFreeDomain 将会续期及跳过的域名，不会提交续期
+// 参数与 RenewFreeDomainContext 相同
+// 返回 可续期域名列表中的所有域名，将会续期的域名状态为 RenewalPlanned，跳过的域名状态为 RenewalNotDue 或 RenewalNotSelected
+// ctx 被取消时中止请求及重试
+func (c *Client) PlanRenewFreeDomainContext(ctx context.Context, domain string, months int) (renewals []*Renewal, err error) {
+	renewals, err = c.planRenewals(ctx, "PlanRenewFreeDomain", domain, months)
+	return
+}
+
+// planRenewals 读取可续期域名列表，按续期有效期及参数 domain 决定每个域名是否续期
+// 返回 所有域名
+func (c *Client) planRenewals(ctx context.Context, op, domain string, months int) (renewals []*Renewal, err error) {
+	if jar, _ := c.session(); nil == jar {
+		err = ErrNotLoggedIn
+		return
+	}
+
+	if months < 1 || months > 12 {
+		err = ErrInvalidPeriod
+		return
+	}
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    op + " Renewals",
+		path:    domainsPath + "?a=renewals",
+		referer: loginPath,
+	})
+	if nil != err {
+		return
+	}
+
+	var page *scrape.RenewalsPage
+	page, err = scrape.ParseRenewals(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("%s %w", op, err)
+		return
+	}
+
+	for _, row := range page.Domains {
+		r := &Renewal{
+			Domain:   row.Domain,
+			DomainID: row.ID,
+			DaysLeft: row.DaysLeft,
+		}
+
+		if row.DaysLeft > renewableDays { // 未到续期有效期内
+			r.Status = RenewalNotDue
+		} else if "" != domain && !strings.EqualFold(domain, row.Domain) {
+			r.Status = RenewalNotSelected
+		} else {
+			r.Status = RenewalPlanned
+			r.Months = months
+		}
+
+		renewals = append(renewals, r)
+	}
+
+	return
+}
+
+// submitRenewals 依次提交状态为 RenewalPlanned 的域名的续期，请求出错时中止
+// 每次提交前重新读取 token，提交过程中重新登录后之后的续期使用新的 token
+func (c *Client) submitRenewals(ctx context.Context, renewals []*Renewal) (err error) {
+	for _, r := range renewals {
+		if RenewalPlanned != r.Status {
+			continue
+		}
+
+		_, token := c.session()
+		if err = c.submitRenewal(ctx, token, r); nil != err {
+			return
+		}
+	}
+
+	return
+}
+
+// submitRenewal 提交一个域名的续期，结果写入 r
+// 返回 请求出错或页面无法解析时的错误，此时 r 的状态为 RenewalFailed
+func (c *Client) submitRenewal(ctx context.Context, token string, r *Renewal) (err error) {
+	defer func() {
+		if nil != err {
+			r.Status = RenewalFailed
+			r.Err = err
+		}
+	}()
+
+	params := url.Values{}
+	params.Add("token", token)
+	params.Add("renewalid", r.DomainID)
+	params.Add(fmt.Sprintf("renewalperiod[%s]", r.DomainID), fmt.Sprintf("%dM", r.Months))
+	params.Add("paymentmethod", "credit")
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:    fmt.Sprintf("RenewFreeDomain SubmitRenewals %s", r.Domain),
+		method:  "POST",
+		path:    domainsPath + "?submitrenewals=true",
+		form:    params,
+		referer: domainsPath + "?a=renewdomain&domain=" + r.DomainID,
+		noRetry: true,
+	})
+	if nil != err {
+		return
+	}
+
+	var result *scrape.RenewalResultPage
+	result, err = scrape.ParseRenewalResult(bytes.NewReader(all))
+	if nil != err {
+		err = fmt.Errorf("RenewFreeDomain %w", err)
+		return
+	}
+
+	if !result.Success { // 续期失败
+		msg := result.Message
+		if "" == msg {
+			msg = "renewal not confirmed"
+		}
+
+		r.Status = RenewalFailed
+		r.Err = &ManageError{Op: "RenewFreeDomain", Domain: r.Domain, Message: msg}
+		return
+	}
+
+	r.Status = RenewalSuccess
+	r.OrderNumber = result.OrderNumber
+	return
+}
@@ -0,0 +1,202 @@
+package freenom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/freenom/internal/scrape"
+	"github.com/tzwsoho/go-freenom/internal/atomicfile"
+)
+
+// SessionCookie 会话中保存的 cookie
+type SessionCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Session 可持久化的会话，包含 cookie、令牌及域名 ID 缓存
+type Session struct {
+	BaseURL string           `json:"base_url"`
+	Token   string           `json:"token"`
+	Cookies []*SessionCookie `json:"cookies"`
+	Domains []*DomainInfo    `json:"domains"`
+	SavedAt time.Time        `json:"saved_at"`
+}
+
+// Session 获取当前会话的快照，未登录时返回 ErrNotLoggedIn
+func (c *Client) Session() (sess *Session, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if nil == c.jar {
+		return nil, ErrNotLoggedIn
+	}
+
+	sess = &Session{
+		BaseURL: c.baseURL.String(),
+		Token:   c.token,
+		SavedAt: time.Now(),
+	}
+
+	for _, ck := range c.jar.Cookies(c.baseURL) {
+		sess.Cookies = append(sess.Cookies, &SessionCookie{
+			Name:  ck.Name,
+			Value: ck.Value,
+		})
+	}
+
+	for _, info := range c.domainInfoMap {
+		if nil == info {
+			continue
+		}
+
+		v := *info
+		sess.Domains = append(sess.Domains, &v)
+	}
+
+	return
+}
+
+// SetSession 使用保存的会话替换当前会话，不校验会话是否仍然有效
+// 会话为 nil 或 BaseURL 与客户端的站点不同时返回 ErrInvalidSession，BaseURL 为空时不检查
+func (c *Client) SetSession(sess *Session) (err error) {
+	if nil == sess {
+		return fmt.Errorf("SetSession nil session: %w", ErrInvalidSession)
+	}
+
+	if "" != sess.BaseURL && strings.TrimSuffix(sess.BaseURL, "/") != strings.TrimSuffix(c.baseURL.String(), "/") {
+		return fmt.Errorf("SetSession session saved for %s: %w", sess.BaseURL, ErrInvalidSession)
+	}
+
+	var jar *cookiejar.Jar
+	jar, err = cookiejar.New(nil)
+	if nil != err {
+		return fmt.Errorf("SetSession New Jar err: %w", err)
+	}
+
+	cookies := make([]*http.Cookie, 0, len(sess.Cookies))
+	for _, ck := range sess.Cookies {
+		if nil == ck { // 手动编辑或截断的会话文件中可能有 null
+			continue
+		}
+
+		cookies = append(cookies, &http.Cookie{
+			Name:  ck.Name,
+			Value: ck.Value,
+		})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	jar.SetCookies(c.baseURL, cookies)
+
+	c.jar = jar
+	c.token = sess.Token
+	c.domainInfoMap = make(map[string]*DomainInfo)
+	for _, info := range sess.Domains {
+		if nil == info {
+			continue
+		}
+
+		v := *info
+		c.domainInfoMap[v.Domain] = &v
+	}
+
+	return
+}
+
+// SaveSession 将当前会话以 JSON 格式写入 w
+func (c *Client) SaveSession(w io.Writer) (err error) {
+	var sess *Session
+	sess, err = c.Session()
+	if nil != err {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(sess); nil != err {
+		return fmt.Errorf("SaveSession Encode err: %w", err)
+	}
+
+	return
+}
+
+// LoadSession 从 r 中读取 SaveSession 保存的会话并替换当前会话，不校验会话是否仍然有效
+func (c *Client) LoadSession(r io.Reader) (err error) {
+	var sess Session
+	if err = json.NewDecoder(r).Decode(&sess); nil != err {
+		return fmt.Errorf("LoadSession Decode err: %w", err)
+	}
+
+	return c.SetSession(&sess)
+}
+
+// SaveSessionFile 将当前会话保存到文件
+// 文件中包含登录 cookie，因此只有当前用户可读写
+func (c *Client) SaveSessionFile(path string) (err error) {
+	return atomicfile.Write("SaveSessionFile", path, 0600, c.SaveSession)
+}
+
+// RestoreSession 从文件恢复会话
+// 等同于使用 context.Background() 调用 RestoreSessionContext
+func (c *Client) RestoreSession(path string) (err error) {
+	return c.RestoreSessionContext(context.Background(), path)
+}
+
+// RestoreSessionContext 从文件恢复会话并校验其是否仍然有效
+// 文件不存在、无法解析或会话已过期时，使用客户端保存的账号密码重新登录
+// ctx 被取消时中止请求及重试
+func (c *Client) RestoreSessionContext(ctx context.Context, path string) (err error) {
+	var f *os.File
+	f, err = os.Open(path)
+	if nil == err {
+		err = c.LoadSession(f)
+		f.Close()
+	}
+
+	if nil == err {
+		var valid bool
+		valid, err = c.CheckSessionContext(ctx)
+		if nil != err || valid {
+			return
+		}
+	}
+
+	return c.LoginContext(ctx)
+}
+
+// CheckSession 检查当前会话是否仍处于登录状态
+// 等同于使用 context.Background() 调用 CheckSessionContext
+func (c *Client) CheckSession() (valid bool, err error) {
+	return c.CheckSessionContext(context.Background())
+}
+
+// CheckSessionContext 检查当前会话是否仍处于登录状态，不会自动重新登录
+// ctx 被取消时中止请求及重试
+func (c *Client) CheckSessionContext(ctx context.Context) (valid bool, err error) {
+	if jar, _ := c.session(); nil == jar {
+		return false, nil
+	}
+
+	var all []byte
+	all, err = c.do(ctx, &request{
+		name:         "CheckSession",
+		path:         loginPath,
+		referer:      loginPath,
+		noLoginCheck: true,
+	})
+	if nil != err {
+		return
+	}
+
+	return scrape.LoggedIn(all), nil
+}
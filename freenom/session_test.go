@@ -0,0 +1,136 @@
+package freenom
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveRestoreSession(t *testing.T) {
+	srv := newTestServer(t)
+
+	dir, err := ioutil.TempDir("", "freenom")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "session.json")
+
+	c, _ := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd))
+	if err = c.SaveSessionFile(path); nil == err {
+		t.Error("SaveSessionFile should fail before Login")
+	}
+
+	// 会话文件不存在时直接登录
+	if err = c.RestoreSession(path); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 1 != srv.Logins() {
+		t.Errorf("expect 1 login, got %d", srv.Logins())
+	}
+
+	if _, err = c.ListDomains(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if err = c.SaveSessionFile(path); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if fi, err := os.Stat(path); nil != err || 0600 != fi.Mode().Perm() {
+		t.Errorf("unexpected session file mode: %v %v", fi, err)
+	}
+
+	// 会话有效时不再登录
+	c2, _ := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd))
+	if err = c2.RestoreSession(path); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 1 != srv.Logins() {
+		t.Errorf("restored session should not login again, logins: %d", srv.Logins())
+	}
+
+	if info, ok := c2.cachedDomain(freenomDomain); !ok || "" == info.DomainID {
+		t.Error("domain cache not restored")
+	}
+
+	if _, err = c2.GetDomainInfo(freenomDomain); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	_, token := c.session()
+	if _, token2 := c2.session(); token != token2 {
+		t.Errorf("token not restored: %s != %s", token, token2)
+	}
+
+	// 会话过期后重新登录
+	srv.ExpireSessions()
+
+	c3, _ := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd))
+	if err = c3.RestoreSession(path); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 2 != srv.Logins() {
+		t.Errorf("stale session should login again, logins: %d", srv.Logins())
+	}
+
+	if valid, err := c3.CheckSession(); nil != err || !valid {
+		t.Errorf("session should be valid after restore: %v %v", valid, err)
+	}
+
+	if err = c3.SetSession(nil); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("expect ErrInvalidSession, got %v", err)
+	}
+
+	// 其它站点保存的会话不会被加载，改为重新登录
+	sess, err := c3.Session()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	sess.BaseURL = "https://other.example.com/"
+	if err = c3.SetSession(sess); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("expect ErrInvalidSession, got %v", err)
+	}
+
+	data, _ := json.Marshal(sess)
+	if err = ioutil.WriteFile(path, data, 0600); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	c4, _ := NewClient(WithBaseURL(srv.URL), WithCredentials(freenomUser, freenomPwd))
+	if err = c4.RestoreSession(path); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 3 != srv.Logins() {
+		t.Errorf("session for another site should login again, logins: %d", srv.Logins())
+	}
+}
+
+func TestLoadSessionNullEntries(t *testing.T) {
+	c, _ := NewClient(WithCredentials(freenomUser, freenomPwd))
+
+	// 手动编辑或截断的会话文件中的 null 被忽略
+	err := c.LoadSession(strings.NewReader(`{"token":"abc","cookies":[null,{"name":"a","value":"b"}],"domains":[null,{"Domain":"freenom-api.tk","DomainID":"1"}]}`))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	sess, err := c.Session()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if "abc" != sess.Token || 1 != len(sess.Cookies) || 1 != len(sess.Domains) || "1" != sess.Domains[0].DomainID {
+		t.Errorf("unexpected session %+v", sess)
+	}
+}
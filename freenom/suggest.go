@@ -0,0 +1,288 @@
+package freenom
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const defaultMaxCandidates int = 50
+
+// 生成候选域名时默认使用的常见前缀及后缀
+var (
+	defaultSuggestPrefixes = []string{"get", "my", "the", "go", "try"}
+	defaultSuggestSuffixes = []string{"app", "hq", "hub", "online", "now"}
+)
+
+// SuggestOptions 生成及查询候选域名的设置
+type SuggestOptions struct {
+	Keywords      []string          // 与域名前缀组合的关键词，分别放在前面及后面
+	Prefixes      []string          // 常见前缀，为 nil 时使用 get、my、the 等，为空切片时不使用
+	Suffixes      []string          // 常见后缀，为 nil 时使用 app、hq、hub 等，为空切片时不使用
+	TLDs          []string          // 按偏好排列的后缀，例如 .tk、.ml，为空时不限后缀且不区分偏好
+	IncludePaid   bool              // 是否包括收费及 SPECIAL 域名，默认只返回免费域名
+	MaxCandidates int               // 最多查询的候选前缀数量，小于等于 0 时为 50
+	Limit         int               // 最多返回的建议数量，小于等于 0 时不限制
+	Bulk          *BulkCheckOptions // 批量查询的并发数及限速，其中的 TLDs 会被上面的 TLDs 替换
+}
+
+// Suggestion 一个可以注册的候选域名
+type Suggestion struct {
+	Availability
+	Variant string // 候选的域名前缀
+}
+
+// SuggestDomains 生成候选域名并查询其中可以注册的域名
+// 等同于使用 context.Background() 调用 SuggestDomainsContext
+func (c *Client) SuggestDomains(prefix string, opts *SuggestOptions) (suggestions []*Suggestion, err error) {
+	return c.SuggestDomainsContext(context.Background(), prefix, opts)
+}
+
+// SuggestDomainsContext 根据域名前缀生成连字符、常见前后缀、单复数及关键词组合的候选前缀，
+// 通过 CheckAvailabilityBulkContext 批量查询，返回可以注册的域名
+// 结果依次按前缀长度、后缀偏好、价格及域名排序
+// 部分候选查询失败时忽略失败的候选，全部失败时返回第一个错误
+// 参数 opts 为 nil 时使用默认设置
+// ctx 被取消时中止请求及重试
+func (c *Client) SuggestDomainsContext(ctx context.Context, prefix string, opts *SuggestOptions) (suggestions []*Suggestion, err error) {
+	if nil == opts {
+		opts = &SuggestOptions{}
+	}
+
+	variants := SuggestVariants(prefix, opts)
+	if 0 == len(variants) {
+		err = fmt.Errorf("SuggestDomains invalid prefix %q: %w", prefix, ErrInvalidPrefix)
+		return
+	}
+
+	bulk := BulkCheckOptions{}
+	if nil != opts.Bulk {
+		bulk = *opts.Bulk
+	}
+	bulk.TLDs = opts.TLDs
+
+	tlds := bulkTLDs(opts.TLDs)
+	preference := make(map[string]int)
+	for _, t := range opts.TLDs {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !strings.HasPrefix(t, ".") {
+			t = "." + t
+		}
+
+		if _, ok := preference[t]; !ok && tlds[t] {
+			preference[t] = len(preference)
+		}
+	}
+
+	succeeded := false
+	for res := range c.CheckAvailabilityBulkContext(ctx, variants, &bulk) {
+		if nil != res.Err {
+			if nil == err {
+				err = res.Err
+			}
+			continue
+		}
+
+		succeeded = true
+		for _, a := range res.Result.Domains {
+			if !a.Available() || (!opts.IncludePaid && DomainTypeFree != a.Type) {
+				continue
+			}
+
+			suggestions = append(suggestions, &Suggestion{
+				Availability: *a,
+				Variant:      res.Prefix,
+			})
+		}
+	}
+
+	if e := ctx.Err(); nil != e {
+		return nil, e
+	}
+
+	if !succeeded {
+		err = fmt.Errorf("SuggestDomains err: %w", err)
+		return
+	}
+	err = nil
+
+	rank := func(tld string) int {
+		if i, ok := preference[tld]; ok {
+			return i
+		}
+
+		return len(preference)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		switch {
+		case len(a.Variant) != len(b.Variant):
+			return len(a.Variant) < len(b.Variant)
+
+		case rank(a.TLD) != rank(b.TLD):
+			return rank(a.TLD) < rank(b.TLD)
+
+		case a.Price.Cents != b.Price.Cents:
+			return a.Price.Cents < b.Price.Cents
+		}
+
+		return a.Domain < b.Domain
+	})
+
+	if opts.Limit > 0 && len(suggestions) > opts.Limit {
+		suggestions = suggestions[:opts.Limit]
+	}
+
+	return
+}
+
+// SuggestVariants 生成候选的域名前缀，第一个为规范化后的 prefix 本身
+// 依次包括：去掉连字符、单复数、在单词中的常见前后缀或关键词处插入连字符、常见前后缀以及与关键词的组合，组合时分别生成直接连接及用连字符连接的形式
+// 不符合域名规则的候选会被忽略，最多返回 opts.MaxCandidates 个
+func SuggestVariants(prefix string, opts *SuggestOptions) (variants []string) {
+	if nil == opts {
+		opts = &SuggestOptions{}
+	}
+
+	max := opts.MaxCandidates
+	if max <= 0 {
+		max = defaultMaxCandidates
+	}
+
+	seen := make(map[string]bool)
+	add := func(v string) {
+		if len(variants) < max && !seen[v] && validLabel(v) {
+			seen[v] = true
+			variants = append(variants, v)
+		}
+	}
+
+	base := strings.ToLower(strings.TrimSpace(prefix))
+	if !validLabel(base) {
+		return
+	}
+	add(base)
+
+	words := strings.Split(base, "-")
+	joined := strings.Join(words, "")
+	add(joined)
+	if s := singular(joined); s != joined {
+		add(s)
+	} else {
+		add(plural(joined))
+	}
+
+	prefixes := opts.Prefixes
+	if nil == prefixes {
+		prefixes = defaultSuggestPrefixes
+	}
+
+	suffixes := opts.Suffixes
+	if nil == suffixes {
+		suffixes = defaultSuggestSuffixes
+	}
+
+	if 1 == len(words) {
+		for _, v := range hyphenations(joined, prefixes, suffixes, opts.Keywords) {
+			add(v)
+		}
+	}
+
+	combine := func(a, b string) {
+		add(a + b)
+		add(a + "-" + b)
+	}
+
+	for _, kw := range opts.Keywords {
+		kw = strings.ToLower(strings.TrimSpace(kw))
+		combine(joined, kw)
+		combine(kw, joined)
+	}
+
+	for _, p := range prefixes {
+		combine(strings.ToLower(strings.TrimSpace(p)), joined)
+	}
+
+	for _, s := range suffixes {
+		combine(joined, strings.ToLower(strings.TrimSpace(s)))
+	}
+
+	return
+}
+
+// hyphenations 在单词开头的常见前缀或关键词之后、结尾的常见后缀或关键词之前插入一个连字符生成的候选
+// 例如 mydomain 的 my-domain，两边都至少保留 2 个字符
+func hyphenations(word string, prefixes, suffixes, keywords []string) (variants []string) {
+	done := make(map[int]bool)
+	split := func(i int) {
+		if i >= 2 && i <= len(word)-2 && !done[i] {
+			done[i] = true
+			variants = append(variants, word[:i]+"-"+word[i:])
+		}
+	}
+
+	for _, list := range [][]string{prefixes, keywords} {
+		for _, p := range list {
+			if p = strings.ToLower(strings.TrimSpace(p)); "" != p && strings.HasPrefix(word, p) {
+				split(len(p))
+			}
+		}
+	}
+
+	for _, list := range [][]string{suffixes, keywords} {
+		for _, s := range list {
+			if s = strings.ToLower(strings.TrimSpace(s)); "" != s && strings.HasSuffix(word, s) {
+				split(len(word) - len(s))
+			}
+		}
+	}
+
+	return
+}
+
+// validLabel 判断是否为合法的域名前缀：由字母、数字及连字符组成，不以连字符开头或结尾，不超过 63 个字符
+func validLabel(s string) bool {
+	if "" == s || len(s) > 63 || '-' == s[0] || '-' == s[len(s)-1] {
+		return false
+	}
+
+	for _, r := range s {
+		if !('a' <= r && r <= 'z') && !('0' <= r && r <= '9') && '-' != r {
+			return false
+		}
+	}
+
+	return true
+}
+
+// plural 英文单词的复数形式
+func plural(s string) string {
+	switch {
+	case "" == s:
+		return s
+
+	case strings.HasSuffix(s, "s") || strings.HasSuffix(s, "x") || strings.HasSuffix(s, "z") ||
+		strings.HasSuffix(s, "ch") || strings.HasSuffix(s, "sh"):
+		return s + "es"
+
+	case len(s) > 1 && strings.HasSuffix(s, "y") && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	}
+
+	return s + "s"
+}
+
+// singular 英文单词复数的单数形式，不是复数时原样返回
+func singular(s string) string {
+	switch {
+	case len(s) > 3 && strings.HasSuffix(s, "ies"):
+		return s[:len(s)-3] + "y"
+
+	case len(s) > 1 && strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss"):
+		return s[:len(s)-1]
+	}
+
+	return s
+}
@@ -0,0 +1,99 @@
+package freenom
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSuggestVariants(t *testing.T) {
+	got := SuggestVariants(" Cloud-Box ", &SuggestOptions{
+		Keywords: []string{"dev"},
+		Prefixes: []string{"my"},
+		Suffixes: []string{},
+	})
+
+	want := []string{
+		"cloud-box", "cloudbox", "cloudboxes",
+		"cloudboxdev", "cloudbox-dev", "devcloudbox", "dev-cloudbox",
+		"mycloudbox", "my-cloudbox",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+
+	if got = SuggestVariants("cities", &SuggestOptions{Prefixes: []string{}, Suffixes: []string{}}); !reflect.DeepEqual([]string{"cities", "city"}, got) {
+		t.Errorf("unexpected variants %v", got)
+	}
+
+	// 单个单词只在常见前后缀及关键词处插入连字符
+	got = SuggestVariants("mydomain", &SuggestOptions{Keywords: []string{"dom"}, Prefixes: []string{"my"}, Suffixes: []string{"main"}})
+	if want = []string{"mydomain", "mydomains", "my-domain", "mydo-main"}; !reflect.DeepEqual(want, got[:len(want)]) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+
+	got = SuggestVariants("clouddev", &SuggestOptions{Keywords: []string{"dev"}, Prefixes: []string{}, Suffixes: []string{}})
+	if want = []string{"clouddev", "clouddevs", "cloud-dev"}; !reflect.DeepEqual(want, got[:len(want)]) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+
+	if got = SuggestVariants("abc", &SuggestOptions{Prefixes: []string{}, Suffixes: []string{}}); !reflect.DeepEqual([]string{"abc", "abcs"}, got) {
+		t.Errorf("expect no hyphenation for short words, got %v", got)
+	}
+
+	if got = SuggestVariants("freenom", &SuggestOptions{MaxCandidates: 3}); 3 != len(got) {
+		t.Errorf("expect 3 variants, got %v", got)
+	}
+
+	if got = SuggestVariants("bad.prefix", nil); 0 != len(got) {
+		t.Errorf("expect no variants, got %v", got)
+	}
+}
+
+func TestSuggestDomains(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Take("freenom-x.tk")
+	srv.Take("freenomx.tk")
+
+	suggestions, err := DefaultClient().SuggestDomains("freenom-x", &SuggestOptions{
+		Prefixes: []string{"my"},
+		Suffixes: []string{},
+		TLDs:     []string{".tk", "ml"},
+		Limit:    4,
+	})
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	var got []string
+	for _, s := range suggestions {
+		got = append(got, s.Domain)
+		if !s.Available() || DomainTypeFree != s.Type {
+			t.Errorf("unexpected suggestion %+v", s)
+		}
+	}
+
+	// freenomx.tk、freenom-x.tk 已被注册，长度相同时 .tk 优先
+	want := []string{"freenomx.ml", "freenom-x.ml", "freenomxes.tk", "myfreenomx.tk"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %v, got %v", want, got)
+	}
+
+	paid, err := DefaultClient().SuggestDomains("freenom-x", &SuggestOptions{
+		Prefixes:    []string{},
+		Suffixes:    []string{},
+		TLDs:        []string{".com"},
+		IncludePaid: true,
+	})
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if 0 == len(paid) || DomainTypePaid != paid[0].Type || paid[0].Price.IsFree() {
+		t.Errorf("unexpected paid suggestions %+v", paid)
+	}
+
+	if _, err = DefaultClient().SuggestDomains("-", nil); !errors.Is(err, ErrInvalidPrefix) {
+		t.Errorf("expect ErrInvalidPrefix, got %v", err)
+	}
+}
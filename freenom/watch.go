@@ -0,0 +1,416 @@
+package freenom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tzwsoho/go-freenom/internal/atomicfile"
+)
+
+const defaultWatchInterval time.Duration = time.Minute * 10
+
+// WatchEntry 关注列表中的一个域名
+type WatchEntry struct {
+	Domain     string    `json:"domain"`                // 小写的完整域名
+	AddedAt    time.Time `json:"added_at"`              // 加入关注列表的时间
+	CheckedAt  time.Time `json:"checked_at,omitempty"`  // 最近一次查询成功的时间
+	Available  bool      `json:"available"`             // 最近一次查询时是否可以免费注册
+	NotifiedAt time.Time `json:"notified_at,omitempty"` // 最近一次所有处理函数都处理成功的时间
+	Pending    []int     `json:"pending,omitempty"`     // 处理失败、下次查询时需要再次调用的处理函数序号，按 WithWatchHandler 的添加顺序从 0 开始
+}
+
+// watchlistFile 关注列表文件的内容
+type watchlistFile struct {
+	Domains []*WatchEntry `json:"domains"`
+}
+
+// Watchlist 可持久化的关注列表，可在多个协程中同时使用
+type Watchlist struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]*WatchEntry
+}
+
+// LoadWatchlist 从文件读取关注列表，文件不存在时返回空的关注列表
+// 文件中的域名统一转为小写，重复的域名只保留第一个
+// 参数 path 为空时关注列表只保存在内存中
+func LoadWatchlist(path string) (list *Watchlist, err error) {
+	list = &Watchlist{
+		path:    path,
+		entries: make(map[string]*WatchEntry),
+	}
+
+	if "" == path {
+		return
+	}
+
+	var f *os.File
+	f, err = os.Open(path)
+	if os.IsNotExist(err) {
+		return list, nil
+	} else if nil != err {
+		return nil, fmt.Errorf("LoadWatchlist Open err: %w", err)
+	}
+	defer f.Close()
+
+	var file watchlistFile
+	if err = json.NewDecoder(f).Decode(&file); nil != err {
+		return nil, fmt.Errorf("LoadWatchlist Decode err: %w", err)
+	}
+
+	for _, e := range file.Domains {
+		if nil == e {
+			continue
+		}
+
+		domain, ok := watchDomain(e.Domain)
+		if !ok {
+			continue
+		}
+
+		e.Domain = domain
+		if _, ok = list.entries[e.Domain]; !ok {
+			list.entries[e.Domain] = e
+		}
+	}
+
+	return
+}
+
+// watchDomain 检查并转换为小写的域名，前缀及后缀都必须是单个合法的标签，例如 foo.tk
+func watchDomain(domain string) (normalized string, ok bool) {
+	prefix, tld, ok := splitDomain(domain)
+	if !ok || !validLabel(prefix) || !validLabel(tld[1:]) {
+		return "", false
+	}
+
+	return prefix + tld, true
+}
+
+// Add 将域名加入关注列表，返回是否为新加入的域名
+// 域名不区分大小写，Foo.tk 与 foo.tk 为同一个域名
+func (l *Watchlist) Add(domain string) (added bool, err error) {
+	normalized, ok := watchDomain(domain)
+	if !ok {
+		return false, fmt.Errorf("Watchlist invalid domain %q: %w", domain, ErrInvalidPrefix)
+	}
+	domain = normalized
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok = l.entries[domain]; ok {
+		return
+	}
+
+	l.entries[domain] = &WatchEntry{
+		Domain:  domain,
+		AddedAt: time.Now(),
+	}
+
+	return true, nil
+}
+
+// Remove 将域名移出关注列表，返回域名是否在列表中
+func (l *Watchlist) Remove(domain string) (removed bool) {
+	prefix, tld, ok := splitDomain(domain)
+	if !ok {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, removed = l.entries[prefix+tld]; removed {
+		delete(l.entries, prefix+tld)
+	}
+
+	return
+}
+
+// Entries 按域名排序的关注列表快照
+func (l *Watchlist) Entries() (entries []WatchEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries = make([]WatchEntry, 0, len(l.entries))
+	for _, e := range l.entries {
+		entry := *e
+		entry.Pending = append([]int(nil), e.Pending...)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Domain < entries[j].Domain
+	})
+
+	return
+}
+
+// Save 将关注列表写回 LoadWatchlist 时的文件，只保存在内存中时不做任何操作
+func (l *Watchlist) Save() (err error) {
+	if "" == l.path {
+		return
+	}
+
+	file := watchlistFile{
+		Domains: make([]*WatchEntry, 0),
+	}
+	for _, e := range l.Entries() {
+		e := e
+		file.Domains = append(file.Domains, &e)
+	}
+
+	return atomicfile.Write("Watchlist Save", l.path, 0644, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(&file); nil != err {
+			return fmt.Errorf("Watchlist Save Encode err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// update 记录一次查询的结果
+// 参数 handlers 处理函数的数量
+// 返回 是否需要通知，以及需要调用的处理函数序号：
+// 域名从不可注册变为可以注册时为所有处理函数，之后仍可注册时为上次处理失败的处理函数
+func (l *Watchlist) update(domain string, available bool, now time.Time, handlers int) (notify bool, pending []int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[domain]
+	if !ok { // 查询期间已被移出关注列表
+		return
+	}
+
+	e.CheckedAt = now
+	switch {
+	case !available:
+		e.Pending = nil
+
+	case !e.Available:
+		notify = true
+		for i := 0; i < handlers; i++ {
+			pending = append(pending, i)
+		}
+
+	case 0 != len(e.Pending):
+		notify = true
+		pending = append(pending, e.Pending...)
+	}
+
+	e.Available = available
+	return
+}
+
+// notified 记录通知的结果
+// 参数 failed 处理失败的处理函数序号，下次查询时只再次调用这些处理函数
+func (l *Watchlist) notified(domain string, failed []int, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, found := l.entries[domain]; found {
+		e.Pending = failed
+		if 0 == len(failed) {
+			e.NotifiedAt = now
+		}
+	}
+}
+
+// WatchHandler 处理关注的域名变为可以免费注册的事件
+// 返回错误时下次查询只会再次调用该处理函数，已处理成功的处理函数不会重复调用
+type WatchHandler interface {
+	DomainAvailable(ctx context.Context, domain string) error
+}
+
+// WatchHandlerFunc 将普通函数用作 WatchHandler
+type WatchHandlerFunc func(ctx context.Context, domain string) error
+
+// DomainAvailable 调用 f 处理事件
+func (f WatchHandlerFunc) DomainAvailable(ctx context.Context, domain string) error {
+	return f(ctx, domain)
+}
+
+// AddToCartHandler 将可以注册的域名加入 c 的购物车，之后可以由人工结账
+func AddToCartHandler(c *Client) WatchHandler {
+	return WatchHandlerFunc(func(ctx context.Context, domain string) error {
+		return c.AddToCartContext(ctx, domain)
+	})
+}
+
+// WatchOption 关注列表轮询的设置
+type WatchOption func(w *Watcher)
+
+// WithWatchInterval 设置轮询间隔，默认为 10 分钟
+func WithWatchInterval(interval time.Duration) WatchOption {
+	return func(w *Watcher) {
+		if interval > 0 {
+			w.interval = interval
+		}
+	}
+}
+
+// WithWatchJitter 设置轮询间隔的随机偏差，每次等待的时间在 间隔±jitter 之间，默认为 0
+func WithWatchJitter(jitter time.Duration) WatchOption {
+	return func(w *Watcher) {
+		if jitter > 0 {
+			w.jitter = jitter
+		}
+	}
+}
+
+// WithWatchHandler 添加处理函数，可以多次使用，按添加的顺序调用
+func WithWatchHandler(h WatchHandler) WatchOption {
+	return func(w *Watcher) {
+		w.handlers = append(w.handlers, h)
+	}
+}
+
+// WithWatchErrorHandler 设置查询、处理函数或保存关注列表出错时的回调，默认忽略错误
+func WithWatchErrorHandler(f func(err error)) WatchOption {
+	return func(w *Watcher) {
+		w.onError = f
+	}
+}
+
+// Watcher 定期查询关注列表中的域名，在域名变为可以免费注册时调用处理函数
+// 同一域名在再次变为不可注册之前只通知一次，通知状态保存在关注列表文件中，重启后不会重复通知
+type Watcher struct {
+	client   *Client
+	list     *Watchlist
+	interval time.Duration
+	jitter   time.Duration
+	handlers []WatchHandler
+	onError  func(err error)
+}
+
+// NewWatcher 创建使用 c 查询 list 中域名的 Watcher
+func NewWatcher(c *Client, list *Watchlist, opts ...WatchOption) *Watcher {
+	w := &Watcher{
+		client:   c,
+		list:     list,
+		interval: defaultWatchInterval,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Run 立即查询一次，之后按间隔轮询，直到 ctx 被取消
+// 返回 ctx.Err()
+func (w *Watcher) Run(ctx context.Context) error {
+	for {
+		w.CheckOnce(ctx)
+
+		timer := time.NewTimer(w.nextInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+
+		case <-timer.C:
+		}
+	}
+}
+
+// nextInterval 加上随机偏差后的下一次轮询间隔
+func (w *Watcher) nextInterval() time.Duration {
+	d := w.interval
+	if w.jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(w.jitter)*2+1)) - w.jitter
+	}
+
+	if d <= 0 {
+		d = w.interval
+	}
+
+	return d
+}
+
+// CheckOnce 查询一次关注列表中的所有域名，通过 CheckFreeDomainPurchasable 按域名前缀查询
+// 某个前缀查询失败不影响其它前缀，所有错误都会传给 WithWatchErrorHandler 设置的回调
+// 返回 本次新变为可以注册并已通知的域名，以及遇到的第一个错误
+func (w *Watcher) CheckOnce(ctx context.Context) (available []string, err error) {
+	report := func(e error) {
+		if nil == err {
+			err = e
+		}
+
+		if nil != w.onError {
+			w.onError(e)
+		}
+	}
+
+	byPrefix := make(map[string][]string)
+	var prefixes []string
+	for _, e := range w.list.Entries() {
+		prefix, _, _ := splitDomain(e.Domain)
+		if _, ok := byPrefix[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+
+		byPrefix[prefix] = append(byPrefix[prefix], e.Domain)
+	}
+
+	for _, prefix := range prefixes {
+		if nil != ctx.Err() {
+			report(ctx.Err())
+			break
+		}
+
+		free, e := w.client.CheckFreeDomainPurchasableContext(ctx, prefix)
+		if nil != e {
+			report(fmt.Errorf("Watcher check %s err: %w", prefix, e))
+			continue
+		}
+
+		isFree := make(map[string]bool) // 关注列表中的域名均为小写
+		for _, d := range free {
+			isFree[strings.ToLower(d)] = true
+		}
+
+		now := time.Now()
+		for _, domain := range byPrefix[prefix] {
+			notify, pending := w.list.update(domain, isFree[domain], now, len(w.handlers))
+			if !notify {
+				continue
+			}
+
+			var failed []int
+			for _, i := range pending {
+				if i >= len(w.handlers) { // 重启后处理函数变少
+					continue
+				}
+
+				if e = w.handlers[i].DomainAvailable(ctx, domain); nil != e {
+					failed = append(failed, i)
+					report(fmt.Errorf("Watcher handle %s err: %w", domain, e))
+				}
+			}
+
+			w.list.notified(domain, failed, now)
+			if 0 == len(failed) {
+				available = append(available, domain)
+			}
+		}
+	}
+
+	if e := w.list.Save(); nil != e {
+		report(e)
+	}
+
+	return
+}
@@ -0,0 +1,186 @@
+package freenom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchlist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watchlist")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "watchlist.json")
+	list, err := LoadWatchlist(path)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	for _, domain := range []string{"Freenom-W.tk", "freenom-w.ml", "freenom-w.tk"} {
+		if _, err = list.Add(domain); nil != err {
+			t.Fatal(err.Error())
+		}
+	}
+
+	for _, bad := range []string{"freenom", "foo bar.tk", "foo_bar.tk", "foo.tk.ml", "-foo.tk"} {
+		if _, err = list.Add(bad); !errors.Is(err, ErrInvalidPrefix) {
+			t.Errorf("expect ErrInvalidPrefix for %q, got %v", bad, err)
+		}
+	}
+
+	if !list.Remove("freenom-w.ml") || list.Remove("freenom-w.ml") {
+		t.Error("unexpected remove result")
+	}
+
+	if err = list.Save(); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if list, err = LoadWatchlist(path); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if entries := list.Entries(); 1 != len(entries) || "freenom-w.tk" != entries[0].Domain {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	// 手工编辑的文件中大小写不同的域名视为同一个
+	err = ioutil.WriteFile(path, []byte(`{"domains": [{"domain": " Foo.TK"}, {"domain": "foo.tk"}, {"domain": "bad"}, {"domain": "foo_bar.tk"}, {"domain": "foo.tk.ml"}, null]}`), 0644)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if list, err = LoadWatchlist(path); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if added, _ := list.Add("FOO.tk"); added {
+		t.Error("expect FOO.tk to be watched already")
+	}
+
+	if entries := list.Entries(); 1 != len(entries) || "foo.tk" != entries[0].Domain {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Take("freenom-w.tk")
+	srv.Take("freenom-w.cf")
+
+	list, _ := LoadWatchlist("")
+	list.Add("freenom-w.tk")
+	list.Add("freenom-w.cf")
+
+	var notified []string
+	fail := true
+	w := NewWatcher(DefaultClient(), list,
+		WithWatchHandler(WatchHandlerFunc(func(ctx context.Context, domain string) error {
+			notified = append(notified, domain)
+			return nil
+		})),
+		WithWatchHandler(WatchHandlerFunc(func(ctx context.Context, domain string) error {
+			if "freenom-w.cf" == domain && fail {
+				return errors.New("handler failed")
+			}
+
+			return nil
+		})),
+	)
+
+	if available, err := w.CheckOnce(context.Background()); nil != err || 0 != len(available) {
+		t.Fatalf("unexpected result %v, %v", available, err)
+	}
+
+	srv.Release("freenom-w.tk")
+	srv.Release("freenom-w.cf")
+
+	available, err := w.CheckOnce(context.Background())
+	if !reflect.DeepEqual([]string{"freenom-w.tk"}, available) || nil == err {
+		t.Errorf("unexpected result %v, %v", available, err)
+	}
+
+	if e := list.Entries()[0]; "freenom-w.cf" != e.Domain || !reflect.DeepEqual([]int{1}, e.Pending) {
+		t.Errorf("expect the failed handler to be pending, got %+v", e)
+	}
+
+	// 通知过的域名不再重复通知，处理失败的域名只再次调用失败的处理函数
+	fail = false
+	if available, err = w.CheckOnce(context.Background()); !reflect.DeepEqual([]string{"freenom-w.cf"}, available) || nil != err {
+		t.Errorf("unexpected result %v, %v", available, err)
+	}
+
+	// 再次被注册后重新开放时再次通知
+	srv.Take("freenom-w.tk")
+	w.CheckOnce(context.Background())
+	srv.Release("freenom-w.tk")
+	if available, _ = w.CheckOnce(context.Background()); !reflect.DeepEqual([]string{"freenom-w.tk"}, available) {
+		t.Errorf("unexpected result %v", available)
+	}
+
+	want := []string{"freenom-w.cf", "freenom-w.tk", "freenom-w.tk"}
+	if !reflect.DeepEqual(want, notified) {
+		t.Errorf("expect notified %v, got %v", want, notified)
+	}
+
+	for _, e := range list.Entries() {
+		if !e.Available || e.NotifiedAt.IsZero() || 0 != len(e.Pending) {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err = NewWatcher(DefaultClient(), list, WithWatchInterval(10*time.Millisecond), WithWatchJitter(5*time.Millisecond)).Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWatcherMixedCase(t *testing.T) {
+	srv := newTestServer(t)
+
+	// 查询结果中的域名大小写与关注列表不同
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if nil != err || "/includes/domains/fn-available.php" != req.URL.Path {
+			return resp, err
+		}
+
+		defer resp.Body.Close()
+		all, err := ioutil.ReadAll(resp.Body)
+		if nil != err {
+			return nil, err
+		}
+
+		all = bytes.ReplaceAll(all, []byte(`"freenom-w"`), []byte(`"Freenom-W"`))
+		all = bytes.ReplaceAll(all, []byte(`".tk"`), []byte(`".TK"`))
+		resp.Body = ioutil.NopCloser(bytes.NewReader(all))
+		resp.ContentLength = int64(len(all))
+		resp.Header.Del("Content-Length")
+		return resp, nil
+	})
+
+	c, err := NewClient(WithBaseURL(srv.URL), WithTransport(rt))
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	list, _ := LoadWatchlist("")
+	list.Add("freenom-w.tk")
+
+	available, err := NewWatcher(c, list).CheckOnce(context.Background())
+	if !reflect.DeepEqual([]string{"freenom-w.tk"}, available) || nil != err {
+		t.Errorf("unexpected result %v, %v", available, err)
+	}
+}
@@ -1,5 +1,8 @@
-module freenom
+module github.com/tzwsoho/go-freenom
 
 go 1.14
 
-require github.com/tzwsoho/go-freenom v0.0.0-20201109024018-fe2c93cab446
+require (
+	golang.org/x/net v0.0.0-20210405180319-a5a99cb37ef4
+	gopkg.in/yaml.v2 v2.4.0
+)
@@ -0,0 +1,42 @@
+// Package atomicfile 先写入临时文件再改名替换目标文件，
+// 避免写到一半或中途退出时文件损坏
+package atomicfile
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Write 通过 write 写入同一目录下的临时文件，设置权限为 perm 后改名为 path
+// 出错时删除临时文件，path 原有的内容保持不变，错误以 op 开头
+func Write(op, path string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	var f *os.File
+	f, err = ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if nil != err {
+		return fmt.Errorf("%s TempFile err: %w", op, err)
+	}
+	defer os.Remove(f.Name())
+
+	if err = f.Chmod(perm); nil != err {
+		f.Close()
+		return fmt.Errorf("%s Chmod err: %w", op, err)
+	}
+
+	if err = write(f); nil != err {
+		f.Close()
+		return
+	}
+
+	if err = f.Close(); nil != err {
+		return fmt.Errorf("%s Close err: %w", op, err)
+	}
+
+	if err = os.Rename(f.Name(), path); nil != err {
+		return fmt.Errorf("%s Rename err: %w", op, err)
+	}
+
+	return
+}
@@ -0,0 +1,57 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomicfile")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+	for _, perm := range []os.FileMode{0600, 0644} {
+		if err = Write("Test", path, perm, func(w io.Writer) error {
+			_, err := io.WriteString(w, perm.String())
+			return err
+		}); nil != err {
+			t.Fatal(err.Error())
+		}
+
+		if fi, err := os.Stat(path); nil != err || perm != fi.Mode().Perm() {
+			t.Errorf("expect mode %v, got %v %v", perm, fi, err)
+		}
+
+		if all, _ := ioutil.ReadFile(path); perm.String() != string(all) {
+			t.Errorf("unexpected content %q", all)
+		}
+	}
+
+	// 写入失败时保留原文件，不留下临时文件
+	errWrite := errors.New("write failed")
+	if err = Write("Test", path, 0600, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errWrite
+	}); !errors.Is(err, errWrite) {
+		t.Errorf("expect write error, got %v", err)
+	}
+
+	if all, _ := ioutil.ReadFile(path); "-rw-r--r--" != string(all) {
+		t.Errorf("file changed after failed write: %q", all)
+	}
+
+	if names, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); 0 != len(names) {
+		t.Errorf("temp files left: %v", names)
+	}
+
+	if err = Write("Test", filepath.Join(dir, "missing", "state.json"), 0600, func(w io.Writer) error { return nil }); nil == err {
+		t.Error("expect error writing to a missing directory")
+	}
+}